@@ -0,0 +1,45 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "hash"
+
+// Algorithm is implemented by BLAKE256 and BLAKE224. It allows generic
+// code to be parameterized by a hash function, for example:
+//
+//	func Address[A blake256.Algorithm](data []byte) []byte {
+//		var a A
+//		h := a.New()
+//		h.Write(data)
+//		return h.Sum(nil)
+//	}
+//
+// Any type with the same methods can be used in place of Algorithm as
+// a constraint, which includes wrappers of other hash functions.
+type Algorithm interface {
+	New() hash.Hash
+	Size() int
+}
+
+// BLAKE256 is an Algorithm computing BLAKE-256 checksums.
+type BLAKE256 struct{}
+
+// New returns a new hash.Hash computing the BLAKE-256 checksum.
+func (BLAKE256) New() hash.Hash { return New() }
+
+// Size returns the size of BLAKE-256 checksum in bytes.
+func (BLAKE256) Size() int { return Size }
+
+// BLAKE224 is an Algorithm computing BLAKE-224 checksums.
+type BLAKE224 struct{}
+
+// New returns a new hash.Hash computing the BLAKE-224 checksum.
+func (BLAKE224) New() hash.Hash { return New224() }
+
+// Size returns the size of BLAKE-224 checksum in bytes.
+func (BLAKE224) Size() int { return Size224 }
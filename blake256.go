@@ -7,9 +7,21 @@
 
 // Package blake256 implements BLAKE-256 and BLAKE-224 hash functions (SHA-3
 // candidate).
+//
+// It implements the final version of BLAKE, which uses 14 rounds; earlier
+// 10-round versions produce different checksums.
 package blake256
 
-import "hash"
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+)
 
 // The block size of the hash algorithm in bytes.
 const BlockSize = 64
@@ -20,7 +32,26 @@ const Size = 32
 // The size of BLAKE-224 hash in bytes.
 const Size224 = 28
 
-type digest struct {
+// maxLength is the maximum message length in bytes, such that the length
+// in bits fits into the 64-bit counter.
+const maxLength = 1<<61 - 1
+
+// ErrTooLong is returned by Write when the total message length would
+// exceed 2^61-1 bytes.
+var ErrTooLong = errors.New("blake256: message too long")
+
+// ErrSaltLength is returned when salt is not 16 bytes long.
+var ErrSaltLength = errors.New("blake256: salt must be 16 bytes")
+
+var errPartialByte = errors.New("blake256: write after partial byte")
+
+var errClosed = errors.New("blake256: use of closed digest")
+
+// Digest represents the partial evaluation of a BLAKE-256 or BLAKE-224
+// checksum. Hashes returned by New, New224, NewSalt and New224Salt are of
+// this type. The zero value is not usable; create it with NewDigest or
+// NewDigest224.
+type Digest struct {
 	hashSize int             // hash output size in bits (224 or 256)
 	h        [8]uint32       // current chain value
 	s        [4]uint32       // salt (zero by default)
@@ -28,6 +59,11 @@ type digest struct {
 	nullt    bool            // special case for finalization: skip counter
 	x        [BlockSize]byte // buffer for data not yet compressed
 	nx       int             // number of bytes in buffer
+	nbits    int             // number of bits used in the last buffered byte (0 if all)
+	blocks   int             // number of compressed blocks
+	closed   bool            // set by Close
+
+	tweak func(block uint64) [4]uint32 // per-block salt tweak (nil by default)
 }
 
 var (
@@ -39,12 +75,22 @@ var (
 	iv224 = [8]uint32{
 		0xC1059ED8, 0x367CD507, 0x3070DD17, 0xF70E5939,
 		0xFFC00B31, 0x68581511, 0x64F98FA7, 0xBEFA4FA4}
+)
 
-	pad = [64]byte{0x80}
+// No object identifier (OID) is assigned to BLAKE-256 or BLAKE-224.
+// AlgorithmID256 and AlgorithmID224 are placeholder identifiers returned
+// by the AlgorithmID method; protocols that agree on different identifiers
+// should use their own instead.
+const (
+	AlgorithmID256 = "blake256"
+	AlgorithmID224 = "blake224"
 )
 
+// AlgorithmName returns the name of the hash function computed by New.
+func AlgorithmName() string { return "BLAKE-256" }
+
 // Reset resets the state of digest. It leaves salt intact.
-func (d *digest) Reset() {
+func (d *Digest) Reset() {
 	if d.hashSize == 224 {
 		d.h = iv224
 	} else {
@@ -53,24 +99,73 @@ func (d *digest) Reset() {
 	d.t = 0
 	d.nx = 0
 	d.nullt = false
+	d.nbits = 0
+	d.blocks = 0
+}
+
+// ResetIV resets the state of digest like Reset, but uses iv as the initial
+// chain value instead of the standard one. Checksums computed with
+// a non-standard IV are not BLAKE-256 or BLAKE-224 checksums and won't
+// match other implementations; this is intended for custom chaining
+// constructions. Salt is left intact.
+func (d *Digest) ResetIV(iv [8]uint32) {
+	d.Reset()
+	d.h = iv
 }
 
-func (d *digest) Size() int { return d.hashSize >> 3 }
+func (d *Digest) Size() int { return d.hashSize >> 3 }
 
-func (d *digest) BlockSize() int { return BlockSize }
+func (d *Digest) BlockSize() int { return BlockSize }
 
-func (d *digest) Write(p []byte) (nn int, err error) {
+// SizeBits returns the checksum size in bits (224 or 256).
+func (d *Digest) SizeBits() int { return d.hashSize }
+
+// Name returns the name of the hash function: "BLAKE-224" or "BLAKE-256".
+func (d *Digest) Name() string {
+	if d.hashSize == 224 {
+		return "BLAKE-224"
+	}
+	return "BLAKE-256"
+}
+
+// AlgorithmID returns AlgorithmID224 or AlgorithmID256 depending on
+// the checksum size.
+func (d *Digest) AlgorithmID() string {
+	if d.hashSize == 224 {
+		return AlgorithmID224
+	}
+	return AlgorithmID256
+}
+
+// Write adds more data to the running hash. If the total message length
+// would exceed 2^61-1 bytes (2^64-8 bits, the limit of the message counter),
+// it writes only the bytes that fit and returns ErrTooLong.
+//
+// Full blocks of p are compressed directly from p, so callers that write
+// data in multiples of BlockSize never copy it into the buffer.
+func (d *Digest) Write(p []byte) (nn int, err error) {
+	if d.closed {
+		panic(errClosed)
+	}
+	if rem := maxLength - (d.t>>3 + uint64(d.nx)); uint64(len(p)) > rem {
+		p = p[:rem]
+		err = ErrTooLong
+	}
+	if d.nbits != 0 {
+		if len(p) > 0 {
+			return 0, errPartialByte
+		}
+		return 0, err
+	}
 	nn = len(p)
 	if d.nx > 0 {
-		n := len(p)
-		if n > BlockSize-d.nx {
-			n = BlockSize - d.nx
-		}
-		d.nx += copy(d.x[d.nx:], p)
-		if d.nx == BlockSize {
-			block(d, d.x[:])
-			d.nx = 0
+		n := copy(d.x[d.nx:], p)
+		d.nx += n
+		if d.nx < BlockSize {
+			return
 		}
+		block(d, d.x[:])
+		d.nx = 0
 		p = p[n:]
 	}
 	if len(p) >= BlockSize {
@@ -78,14 +173,107 @@ func (d *digest) Write(p []byte) (nn int, err error) {
 		block(d, p[:n])
 		p = p[n:]
 	}
+	d.nx = copy(d.x[:], p)
+	return
+}
+
+// Remaining returns the number of bytes that can still be written before
+// reaching the maximum message length. It returns 0 if the last byte
+// written was partial.
+func (d *Digest) Remaining() uint64 {
+	if d.nbits != 0 {
+		return 0
+	}
+	return maxLength - (d.t>>3 + uint64(d.nx))
+}
+
+// Grow is a hint that the total message length will be totalLen bytes.
+// It doesn't change the result of hashing. It returns ErrTooLong if
+// totalLen exceeds the maximum message length, which would otherwise be
+// reported by Write only after absorbing data.
+func (d *Digest) Grow(totalLen int) error {
+	if totalLen < 0 {
+		return errors.New("blake256: negative length")
+	}
+	if uint64(totalLen) > maxLength {
+		return ErrTooLong
+	}
+	return nil
+}
+
+// writeBits adds the first nbits bits of p to the running hash. If nbits is
+// not a multiple of 8, the trailing bits are taken from the high bits of
+// p[nbits/8], and no more data can be written until Reset. It stays
+// unexported until it is checked against the official bit-oriented KAT
+// files from the SHA-3 competition.
+func (d *Digest) writeBits(p []byte, nbits int) error {
+	if nbits < 0 || nbits > len(p)*8 {
+		return errors.New("blake256: invalid number of bits")
+	}
+	if d.nbits != 0 && nbits > 0 {
+		return errPartialByte
+	}
+	if _, err := d.Write(p[:nbits/8]); err != nil {
+		return err
+	}
+	if r := nbits % 8; r != 0 {
+		if d.t>>3+uint64(d.nx) >= maxLength {
+			return ErrTooLong
+		}
+		d.x[d.nx] = p[nbits/8] & (0xff << (8 - r))
+		d.nx++
+		d.nbits = r
+	}
+	return nil
+}
+
+// BlockWrite compresses p, which must be a multiple of BlockSize bytes long,
+// directly without buffering. It returns an error if p is not block-aligned
+// or if the digest has buffered data from a previous Write.
+func (d *Digest) BlockWrite(p []byte) error {
+	if d.closed {
+		panic(errClosed)
+	}
+	if len(p)%BlockSize != 0 {
+		return errors.New("blake256: BlockWrite input length is not a multiple of block size")
+	}
+	if d.nx != 0 {
+		return errors.New("blake256: BlockWrite with buffered data")
+	}
 	if len(p) > 0 {
-		d.nx = copy(d.x[:], p)
+		block(d, p)
 	}
-	return
+	return nil
 }
 
-// Sum returns the calculated checksum.
-func (d0 *digest) Sum(in []byte) []byte {
+// WriteUint16 writes v as 2 big-endian bytes.
+func (d *Digest) WriteUint16(v uint16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], v)
+	d.Write(b[:])
+}
+
+// WriteUint32 writes v as 4 big-endian bytes.
+func (d *Digest) WriteUint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	d.Write(b[:])
+}
+
+// WriteUint64 writes v as 8 big-endian bytes.
+func (d *Digest) WriteUint64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	d.Write(b[:])
+}
+
+// Sum appends the calculated checksum to in and returns the resulting
+// slice. Like append, it stores the checksum in the backing array of in
+// after len(in) if there is enough capacity; bytes of in are not changed.
+// Chain value words are written
+// most significant byte first, so the result is the same on big- and
+// little-endian hosts.
+func (d0 *Digest) Sum(in []byte) []byte {
 	// Make a copy of d0 so that caller can keep writing and summing.
 	d := *d0
 	sum := d.checkSum()
@@ -95,52 +283,203 @@ func (d0 *digest) Sum(in []byte) []byte {
 	return append(in, sum[:]...)
 }
 
-func (d *digest) checkSum() [Size]byte {
-	nx := uint64(d.nx)
-	l := d.t + nx<<3
-	var len [8]byte
-	len[0] = byte(l >> 56)
-	len[1] = byte(l >> 48)
-	len[2] = byte(l >> 40)
-	len[3] = byte(l >> 32)
-	len[4] = byte(l >> 24)
-	len[5] = byte(l >> 16)
-	len[6] = byte(l >> 8)
-	len[7] = byte(l)
-
-	if nx == 55 {
-		// One padding byte.
-		d.t -= 8
-		if d.hashSize == 224 {
-			d.Write([]byte{0x80})
-		} else {
-			d.Write([]byte{0x81})
-		}
-	} else {
-		if nx < 55 {
-			// Enough space to fill the block.
-			if nx == 0 {
-				d.nullt = true
-			}
-			d.t -= 440 - nx<<3
-			d.Write(pad[0 : 55-nx])
-		} else {
-			// Need 2 compressions.
-			d.t -= 512 - nx<<3
-			d.Write(pad[0 : 64-nx])
-			d.t -= 440
-			d.Write(pad[1:56])
-			d.nullt = true
+// PutSum writes the current checksum into dst and returns the number of
+// bytes written, which is equal to Size(). It panics if dst is too short.
+// The state of the digest is not changed.
+func (d0 *Digest) PutSum(dst []byte) int {
+	n := d0.Size()
+	if len(dst) < n {
+		panic("blake256: PutSum destination too short")
+	}
+	d := *d0
+	sum := d.checkSum()
+	return copy(dst, sum[:n])
+}
+
+// SumVectored writes the current checksum across bufs in order, filling
+// each buffer before moving to the next one, until Size() bytes are
+// written; buffers after that are not changed. It returns an error without
+// writing anything if the total length of bufs is less than Size().
+// The state of the digest is not changed.
+func (d0 *Digest) SumVectored(bufs [][]byte) error {
+	n := d0.Size()
+	total := 0
+	for _, b := range bufs {
+		total += len(b)
+	}
+	if total < n {
+		return errors.New("blake256: SumVectored buffers too short")
+	}
+	d := *d0
+	sum := d.checkSum()
+	p := sum[:n]
+	for _, b := range bufs {
+		if len(p) == 0 {
+			break
 		}
-		if d.hashSize == 224 {
-			d.Write([]byte{0x00})
-		} else {
-			d.Write([]byte{0x01})
+		p = p[copy(b, p):]
+	}
+	return nil
+}
+
+// TentativeSum returns the checksum of the data written so far, including
+// buffered bytes that haven't been compressed yet, without changing the
+// state of the digest, so that writing can continue. It is like Sum, but
+// returns a Hash256. For BLAKE-224, the checksum is in the first Size224
+// bytes and the rest are zero.
+func (d0 *Digest) TentativeSum() Hash256 {
+	d := *d0
+	return d.checkSum()
+}
+
+// SumReset appends the current checksum to in and resets the digest.
+// It is like Sum followed by Reset, but doesn't copy the digest state.
+// Salt is left intact.
+func (d *Digest) SumReset(in []byte) []byte {
+	sum := d.checkSum()
+	d.Reset()
+	return append(in, sum[:d.Size()]...)
+}
+
+// SumThen returns H(Sum(nil) || trailer), where H is an unsalted hash of
+// the same size as d. The state of d is not changed.
+func (d *Digest) SumThen(trailer []byte) []byte {
+	var d2 Digest
+	d2.hashSize = d.hashSize
+	d2.Reset()
+	d2.Write(d.Sum(nil))
+	d2.Write(trailer)
+	return d2.Sum(nil)
+}
+
+// WriteAll writes each chunk in order and returns d, so that calls can be
+// chained, for example:
+//
+//	blake256.NewDigest().WriteAll(a, b).SumHex()
+//
+// It is a convenience for tests and scripts; errors from Write are ignored.
+func (d *Digest) WriteAll(chunks ...[]byte) *Digest {
+	for _, c := range chunks {
+		d.Write(c)
+	}
+	return d
+}
+
+// SumHex returns the current checksum as a lowercase hex string.
+func (d *Digest) SumHex() string {
+	return hex.EncodeToString(d.Sum(nil))
+}
+
+// Fingerprint returns the first nbytes bytes of the current checksum as
+// colon-separated pairs of lowercase hex digits, such as "3f:a0:1c".
+// It panics if nbytes is not between 1 and Size().
+func (d *Digest) Fingerprint(nbytes int) string {
+	if nbytes < 1 || nbytes > d.Size() {
+		panic("blake256: invalid fingerprint length")
+	}
+	sum := d.Sum(nil)
+	b := make([]byte, 0, 3*nbytes-1)
+	for i, c := range sum[:nbytes] {
+		if i > 0 {
+			b = append(b, ':')
 		}
-		d.t -= 8
+		b = hex.AppendEncode(b, []byte{c})
+	}
+	return string(b)
+}
+
+// Base64URL returns the current checksum encoded with unpadded
+// URL-safe base64.
+func (d *Digest) Base64URL() string {
+	return base64.RawURLEncoding.EncodeToString(d.Sum(nil))
+}
+
+// Base32 returns the current checksum encoded with unpadded base32.
+func (d *Digest) Base32() string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(d.Sum(nil))
+}
+
+var sumPool = sync.Pool{
+	New: func() any { return new([Size]byte) },
+}
+
+// SumPooled returns the current checksum in a buffer taken from a pool,
+// and a function that returns the buffer to the pool. The returned slice
+// is valid only until release is called.
+func (d0 *Digest) SumPooled() (sum []byte, release func()) {
+	d := *d0
+	buf := sumPool.Get().(*[Size]byte)
+	*buf = d.checkSum()
+	return buf[:d.Size()], func() { sumPool.Put(buf) }
+}
+
+// padding writes the final padded block(s) into b and returns their
+// length and the message length in bits. The padding consists of 1 bit,
+// zeros, final bit (1 for BLAKE-256, 0 for BLAKE-224) and the 64-bit
+// message length in bits. The final bit is given as marker, which is
+// ORed into the byte before the length.
+func (d *Digest) padding(b *[2 * BlockSize]byte, marker byte) (n int, l uint64) {
+	nx := d.nx
+	m := nx << 3 // message bits in buffer
+	if d.nbits != 0 {
+		m -= 8 - d.nbits
+	}
+	l = d.t + uint64(m)
+
+	copy(b[:], d.x[:nx])
+	b[m>>3] |= 0x80 >> (m & 7)
+	n = BlockSize
+	if m > 446 {
+		// Need 2 compressions.
+		n = 2 * BlockSize
+	}
+	b[n-9] |= marker
+	binary.BigEndian.PutUint64(b[n-8:], l)
+	return
+}
+
+func (d *Digest) checkSum() [Size]byte {
+	if d.hashSize == 224 {
+		return d.finalize(0x00)
+	}
+	return d.finalize(0x01)
+}
+
+// Finalize returns the checksum computed with marker in place of the final
+// padding bit, which is 0x01 for BLAKE-256 and 0x00 for BLAKE-224. Only the
+// lowest bit of the byte that precedes the 64-bit message length is free of
+// padding and message bits, so marker must be 0x00 or 0x01; Finalize panics
+// otherwise. Checksums computed with the other marker are not BLAKE-256 or
+// BLAKE-224 checksums and won't match other implementations; this is
+// intended for experiments and alternative constructions. The state of the
+// digest is not changed.
+func (d0 *Digest) Finalize(marker byte) []byte {
+	if marker > 0x01 {
+		panic("blake256: invalid final marker")
+	}
+	d := *d0
+	sum := d.finalize(marker)
+	return sum[:d.Size()]
+}
+
+func (d *Digest) finalize(marker byte) [Size]byte {
+	if d.closed {
+		panic(errClosed)
+	}
+	// Build padded final block(s) locally and compress them directly.
+	var b [2 * BlockSize]byte
+	n, l := d.padding(&b, marker)
+
+	// The counter of a block that contains no message bits is zero.
+	if d.nx == 0 {
+		d.nullt = true
+	}
+	d.t = l - 512
+	block(d, b[:BlockSize])
+	if n > BlockSize {
+		d.nullt = true
+		block(d, b[BlockSize:n])
 	}
-	d.t -= 64
-	d.Write(len[:])
 
 	var out [Size]byte
 	j := 0
@@ -154,9 +493,47 @@ func (d *digest) checkSum() [Size]byte {
 	return out
 }
 
-func (d *digest) setSalt(s []byte) {
+// Close zeroes the salt, the chain value and buffered data of the digest.
+// The digest can't be used afterwards: Write, BlockWrite and Sum panic,
+// and Reset doesn't make it usable again; only UnmarshalBinary does.
+func (d *Digest) Close() {
+	d.h = [8]uint32{}
+	d.s = [4]uint32{}
+	d.x = [BlockSize]byte{}
+	d.t = 0
+	d.nx = 0
+	d.nbits = 0
+	d.tweak = nil
+	d.closed = true
+}
+
+// SetSalt sets salt to the given 16-byte slice. It should be called
+// before writing any data, or after Reset.
+func (d *Digest) SetSalt(salt []byte) error {
+	if len(salt) != 16 {
+		return ErrSaltLength
+	}
+	d.setSalt(salt)
+	return nil
+}
+
+// RehashWithSalt sets salt, which must be 16 bytes long, resets the
+// digest and returns the checksum of data. The digest is left reset with
+// the new salt, so it can be reused for the next message.
+func (d *Digest) RehashWithSalt(salt, data []byte) ([]byte, error) {
+	if err := d.SetSalt(salt); err != nil {
+		return nil, err
+	}
+	d.Reset()
+	if _, err := d.Write(data); err != nil {
+		return nil, err
+	}
+	return d.SumReset(nil), nil
+}
+
+func (d *Digest) setSalt(s []byte) {
 	if len(s) != 16 {
-		panic("salt length must be 16 bytes")
+		panic(ErrSaltLength)
 	}
 	d.s[0] = uint32(s[0])<<24 | uint32(s[1])<<16 | uint32(s[2])<<8 | uint32(s[3])
 	d.s[1] = uint32(s[4])<<24 | uint32(s[5])<<16 | uint32(s[6])<<8 | uint32(s[7])
@@ -164,17 +541,115 @@ func (d *digest) setSalt(s []byte) {
 	d.s[3] = uint32(s[12])<<24 | uint32(s[13])<<16 | uint32(s[14])<<8 | uint32(s[15])
 }
 
+// Checkpoint holds a saved state of a digest, which can be restored
+// with Rewind.
+type Checkpoint struct {
+	h      [8]uint32
+	t      uint64
+	nullt  bool
+	x      [BlockSize]byte
+	nx     int
+	nbits  int
+	blocks int
+}
+
+// Checkpoint returns the current state of the digest.
+// Salt, size and block tweak are not saved.
+func (d *Digest) Checkpoint() Checkpoint {
+	return Checkpoint{
+		h:      d.h,
+		t:      d.t,
+		nullt:  d.nullt,
+		x:      d.x,
+		nx:     d.nx,
+		nbits:  d.nbits,
+		blocks: d.blocks,
+	}
+}
+
+// Rewind restores the state of the digest saved by Checkpoint.
+func (d *Digest) Rewind(c Checkpoint) {
+	d.h = c.h
+	d.t = c.t
+	d.nullt = c.nullt
+	d.x = c.x
+	d.nx = c.nx
+	d.nbits = c.nbits
+	d.blocks = c.blocks
+}
+
+// Blocks returns the number of blocks compressed since the digest was
+// created or reset. Blocks compressed by Sum during finalization are not
+// counted.
+func (d *Digest) Blocks() int { return d.blocks }
+
+// SetBlockTweak sets a function which is called before compressing each
+// block with the block index (starting from zero after Reset) and returns
+// words that are XORed into the salt for that block only. Passing nil
+// removes the tweak.
+//
+// This is an advanced feature for experimental constructions: the result
+// is not BLAKE-256 and is not interoperable with other implementations,
+// unless fn always returns zero words.
+func (d *Digest) SetBlockTweak(fn func(block uint64) [4]uint32) {
+	d.tweak = fn
+}
+
+// IsSalted reports whether the digest has a nonzero salt. A digest created
+// with an all-zero salt is indistinguishable from an unsalted one and is
+// reported as not salted.
+func (d *Digest) IsSalted() bool {
+	return d.s != [4]uint32{}
+}
+
+// SaltWords returns the salt as four 32-bit words, each parsed from four
+// salt bytes in big-endian order. It doesn't include a tweak set with
+// SetBlockTweak.
+func (d *Digest) SaltWords() [4]uint32 {
+	return d.s
+}
+
+// SaltFromCounter returns a 16-byte salt for use with NewSalt, consisting
+// of the 8-byte base followed by the big-endian 64-bit counter. Distinct
+// counters produce distinct salts for the same base.
+func SaltFromCounter(base [8]byte, counter uint64) (salt [16]byte) {
+	copy(salt[:8], base[:])
+	binary.BigEndian.PutUint64(salt[8:], counter)
+	return
+}
+
 // New returns a new hash.Hash computing the BLAKE-256 checksum.
+//
+// The message is padded as specified for the SHA-3 competition: it is
+// followed by a 1 bit, zero bits, a 1 bit and the 64-bit message length
+// in bits. Only messages whose length is a multiple of 8 bits can be
+// written.
 func New() hash.Hash {
-	return &digest{
+	return &Digest{
+		hashSize: 256,
+		h:        iv256,
+	}
+}
+
+// NewDigest is like New, but returns *Digest.
+func NewDigest() *Digest {
+	return &Digest{
 		hashSize: 256,
 		h:        iv256,
 	}
 }
 
+// NewDigest224 is like New224, but returns *Digest.
+func NewDigest224() *Digest {
+	return &Digest{
+		hashSize: 224,
+		h:        iv224,
+	}
+}
+
 // NewSalt is like New but initializes salt with the given 16-byte slice.
 func NewSalt(salt []byte) hash.Hash {
-	d := &digest{
+	d := &Digest{
 		hashSize: 256,
 		h:        iv256,
 	}
@@ -184,7 +659,7 @@ func NewSalt(salt []byte) hash.Hash {
 
 // New224 returns a new hash.Hash computing the BLAKE-224 checksum.
 func New224() hash.Hash {
-	return &digest{
+	return &Digest{
 		hashSize: 224,
 		h:        iv224,
 	}
@@ -192,7 +667,7 @@ func New224() hash.Hash {
 
 // New224Salt is like New224 but initializes salt with the given 16-byte slice.
 func New224Salt(salt []byte) hash.Hash {
-	d := &digest{
+	d := &Digest{
 		hashSize: 224,
 		h:        iv224,
 	}
@@ -200,18 +675,101 @@ func New224Salt(salt []byte) hash.Hash {
 	return d
 }
 
+// NewSalt2 is like NewSalt, but builds the 16-byte salt from an 8-byte
+// salt followed by an 8-byte context, so that salt8 fills the first two
+// salt words and context8 the last two. Both must be 8 bytes long.
+func NewSalt2(salt8, context8 []byte) (hash.Hash, error) {
+	if len(salt8) != 8 || len(context8) != 8 {
+		return nil, errors.New("blake256: salt and context must be 8 bytes")
+	}
+	var s [16]byte
+	copy(s[:8], salt8)
+	copy(s[8:], context8)
+	return NewSalt(s[:]), nil
+}
+
+// NewSize returns a new hash.Hash computing the BLAKE-224 or BLAKE-256
+// checksum, depending on bits, which must be 224 or 256.
+func NewSize(bits int) (hash.Hash, error) {
+	switch bits {
+	case 224:
+		return New224(), nil
+	case 256:
+		return New(), nil
+	}
+	return nil, errors.New("blake256: unsupported output size")
+}
+
+// Hash256 is a BLAKE-256 checksum returned by Sum256 and the other
+// functions that return a checksum instead of appending it to a slice.
+// It can be compared with == and used as a map key.
+type Hash256 [Size]byte
+
+// Hash224 is a BLAKE-224 checksum returned by Sum224 and SumFile224.
+// It can be compared with == and used as a map key.
+type Hash224 [Size224]byte
+
+// String returns the checksum as a lowercase hex string.
+func (h Hash256) String() string { return hex.EncodeToString(h[:]) }
+
+// Hex returns the checksum as a lowercase hex string.
+func (h Hash256) Hex() string { return hex.EncodeToString(h[:]) }
+
+// Bytes returns a copy of the checksum as a byte slice.
+func (h Hash256) Bytes() []byte { return append([]byte(nil), h[:]...) }
+
+// Format implements fmt.Formatter; see formatSum.
+func (h Hash256) Format(f fmt.State, verb rune) { formatSum(f, verb, h[:]) }
+
+// String returns the checksum as a lowercase hex string.
+func (h Hash224) String() string { return hex.EncodeToString(h[:]) }
+
+// Hex returns the checksum as a lowercase hex string.
+func (h Hash224) Hex() string { return hex.EncodeToString(h[:]) }
+
+// Bytes returns a copy of the checksum as a byte slice.
+func (h Hash224) Bytes() []byte { return append([]byte(nil), h[:]...) }
+
+// Format implements fmt.Formatter; see formatSum.
+func (h Hash224) Format(f fmt.State, verb rune) { formatSum(f, verb, h[:]) }
+
+// formatSum formats a checksum for the %s, %v and %q verbs as its String
+// and for other verbs as its bytes, so that %x prints the same hex as it
+// did when Sum256, Sum224 and the other helpers returned plain byte arrays,
+// instead of encoding the result of String again.
+func formatSum(f fmt.State, verb rune, b []byte) {
+	switch verb {
+	case 's', 'v', 'q':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), hex.EncodeToString(b))
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), b)
+	}
+}
+
 // Sum256 returns the BLAKE-256 checksum of the data.
-func Sum256(data []byte) [Size]byte {
-	var d digest
+func Sum256(data []byte) Hash256 {
+	var d Digest
 	d.hashSize = 256
 	d.Reset()
 	d.Write(data)
 	return d.checkSum()
 }
 
+// Sum256v returns the BLAKE-256 checksum of the concatenation of chunks,
+// without joining them.
+func Sum256v(chunks ...[]byte) Hash256 {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	for _, c := range chunks {
+		d.Write(c)
+	}
+	return d.checkSum()
+}
+
 // Sum224 returns the BLAKE-224 checksum of the data.
-func Sum224(data []byte) (sum224 [Size224]byte) {
-	var d digest
+func Sum224(data []byte) (sum224 Hash224) {
+	var d Digest
 	d.hashSize = 224
 	d.Reset()
 	d.Write(data)
@@ -219,3 +777,22 @@ func Sum224(data []byte) (sum224 [Size224]byte) {
 	copy(sum224[:], sum[:Size224])
 	return
 }
+
+// SumTruncated returns the first outLen bytes of the salted BLAKE-256
+// checksum of the data. Salt must be 16 bytes, and outLen must be between
+// 1 and Size. Truncating the checksum reduces its security.
+func SumTruncated(data, salt []byte, outLen int) ([]byte, error) {
+	if len(salt) != 16 {
+		return nil, ErrSaltLength
+	}
+	if outLen < 1 || outLen > Size {
+		return nil, errors.New("blake256: invalid output length")
+	}
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	d.setSalt(salt)
+	d.Write(data)
+	sum := d.checkSum()
+	return sum[:outLen], nil
+}
@@ -8,10 +8,29 @@
 package blake256
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
+	"io"
+	"math/bits"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 )
 
 func Test256C(t *testing.T) {
@@ -177,9 +196,46 @@ func TestTwoWrites(t *testing.T) {
 	}
 }
 
+func TestWriteAllocs(t *testing.T) {
+	h := New()
+	buf := make([]byte, 1<<20)
+	n := testing.AllocsPerRun(10, func() {
+		h.Write(buf)
+	})
+	if n > 0 {
+		t.Errorf("Write allocated %v times, expected 0", n)
+	}
+}
+
+func TestSmallWrites(t *testing.T) {
+	msg := make([]byte, 1000)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	want := Sum256(msg)
+	for step := 1; step <= 70; step++ {
+		h := New()
+		for i := 0; i < len(msg); i += step {
+			h.Write(msg[i:min(i+step, len(msg))])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("%d-byte writes: expected %x, got %x", step, want, got)
+		}
+	}
+}
+
 var buf_in = make([]byte, 8<<10)
 var buf_out = make([]byte, 32)
 
+// buf_rand holds pseudorandom data, to check that benchmark results
+// don't depend on input being all zeros. The seed is fixed so that
+// runs are comparable.
+var buf_rand = func() []byte {
+	b := make([]byte, 8<<10)
+	rand.New(rand.NewSource(1)).Read(b)
+	return b
+}()
+
 func Benchmark1K(b *testing.B) {
 	b.SetBytes(1024)
 	for i := 0; i < b.N; i++ {
@@ -207,6 +263,90 @@ func Benchmark64(b *testing.B) {
 	}
 }
 
+func BenchmarkParallel1M(b *testing.B) {
+	data := make([]byte, 1<<20)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		SumParallel256(bytes.NewReader(data), 64<<10, runtime.GOMAXPROCS(0))
+	}
+}
+
+func Benchmark1KByteWrites(b *testing.B) {
+	b.SetBytes(1024)
+	for i := 0; i < b.N; i++ {
+		var bench = New()
+		for j := 0; j < 1024; j++ {
+			bench.Write(buf_in[j : j+1])
+		}
+		_ = bench.Sum(buf_out[0:0])
+	}
+}
+
+func Benchmark8KRandom(b *testing.B) {
+	b.SetBytes(int64(len(buf_rand)))
+	for i := 0; i < b.N; i++ {
+		var bench = New()
+		bench.Write(buf_rand)
+		_ = bench.Sum(buf_out[0:0])
+	}
+}
+
+// Benchmark8KAligned writes in 1 KiB chunks, which Write compresses
+// directly without buffering; compare with Benchmark8K.
+func Benchmark8KAligned(b *testing.B) {
+	b.SetBytes(int64(len(buf_in)))
+	for i := 0; i < b.N; i++ {
+		var bench = NewDigest()
+		for p := buf_in; len(p) > 0; p = p[1024:] {
+			bench.Write(p[:1024])
+		}
+		_ = bench.Sum(buf_out[0:0])
+	}
+}
+
+func BenchmarkSaltedLong(b *testing.B) {
+	salt := []byte("0123456789abcdef")
+	b.SetBytes(int64(len(buf_in)))
+	for i := 0; i < b.N; i++ {
+		var bench = NewSalt(salt)
+		bench.Write(buf_in)
+		_ = bench.Sum(buf_out[0:0])
+	}
+}
+
+func Benchmark1KParallel(b *testing.B) {
+	b.SetBytes(1024)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		var out [Size]byte
+		for pb.Next() {
+			var bench = New()
+			bench.Write(buf_in[:1024])
+			_ = bench.Sum(out[:0])
+		}
+	})
+}
+
+// Benchmark8Kx8 reports each implementation of Sum256x8 supported by
+// the CPU; "generic" is scalar Sum256 called eight times.
+func Benchmark8Kx8(b *testing.B) {
+	var in [8][]byte
+	for j := range in {
+		in[j] = buf_in
+	}
+	saved := x8impl.Load()
+	defer x8impl.Store(saved)
+	for _, impl := range x8Implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			x8impl.Store(impl)
+			b.SetBytes(8 * int64(len(buf_in)))
+			for i := 0; i < b.N; i++ {
+				_ = Sum256x8(in)
+			}
+		})
+	}
+}
+
 func Benchmark1KNoAlloc(b *testing.B) {
 	b.SetBytes(1024)
 	for i := 0; i < b.N; i++ {
@@ -227,3 +367,2786 @@ func Benchmark64NoAlloc(b *testing.B) {
 		_ = Sum256(buf_in[:64])
 	}
 }
+
+// benchmarkCyclesPerByte runs f, which hashes n bytes, b.N times and
+// reports cycles per byte measured with the time-stamp counter, for
+// comparison with published figures. Without a cycle counter it reports
+// nanoseconds per byte.
+func benchmarkCyclesPerByte(b *testing.B, n int, f func()) {
+	b.SetBytes(int64(n))
+	b.ResetTimer()
+	start := rdtsc()
+	for i := 0; i < b.N; i++ {
+		f()
+	}
+	end := rdtsc()
+	b.StopTimer()
+	if hasCycleCounter {
+		b.ReportMetric(float64(end-start)/float64(b.N*n), "cycles/B")
+	} else {
+		b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/B")
+	}
+}
+
+// gBits and gShift apply the G function n times using bits.RotateLeft32
+// and shifts for rotations. They are used to compare both forms in one
+// binary; to compare whole hashing, run benchmarks with and without the
+// blake256manualrotate tag.
+func gBits(a, b, c, d uint32, n int) uint32 {
+	for i := 0; i < n; i++ {
+		a += b
+		d = bits.RotateLeft32(d^a, -16)
+		c += d
+		b = bits.RotateLeft32(b^c, -12)
+		a += b
+		d = bits.RotateLeft32(d^a, -8)
+		c += d
+		b = bits.RotateLeft32(b^c, -7)
+	}
+	return a ^ b ^ c ^ d
+}
+
+func gShift(a, b, c, d uint32, n int) uint32 {
+	for i := 0; i < n; i++ {
+		a += b
+		d ^= a
+		d = d>>16 | d<<16
+		c += d
+		b ^= c
+		b = b>>12 | b<<20
+		a += b
+		d ^= a
+		d = d>>8 | d<<24
+		c += d
+		b ^= c
+		b = b>>7 | b<<25
+	}
+	return a ^ b ^ c ^ d
+}
+
+func TestRotateForms(t *testing.T) {
+	if x, y := gBits(cst0, cst1, cst2, cst3, 1000), gShift(cst0, cst1, cst2, cst3, 1000); x != y {
+		t.Errorf("results differ: %08x, %08x", x, y)
+	}
+	for n := 1; n < 32; n++ {
+		if x := uint32(0x80000001); rotr(x, n) != bits.RotateLeft32(x, -n) {
+			t.Errorf("rotr(%08x, %d) = %08x", x, n, rotr(x, n))
+		}
+	}
+}
+
+func BenchmarkRotate(b *testing.B) {
+	var sink uint32
+	b.Run("bits", func(b *testing.B) {
+		sink = gBits(cst0, cst1, cst2, cst3, b.N)
+	})
+	b.Run("shift", func(b *testing.B) {
+		sink = gShift(cst0, cst1, cst2, cst3, b.N)
+	})
+	_ = sink
+}
+
+func BenchmarkCyclesPerByte(b *testing.B) {
+	for _, n := range []int{64, 1024, 8192} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			benchmarkCyclesPerByte(b, n, func() { Sum256(buf_in[:n]) })
+		})
+	}
+}
+
+func refHKDF(secret, salt, info []byte, length int) []byte {
+	if salt == nil {
+		salt = make([]byte, Size)
+	}
+	h := hmac.New(New, salt)
+	h.Write(secret)
+	prk := h.Sum(nil)
+	var out, t []byte
+	for i := 1; len(out) < length; i++ {
+		h = hmac.New(New, prk)
+		h.Write(t)
+		h.Write(info)
+		h.Write([]byte{byte(i)})
+		t = h.Sum(nil)
+		out = append(out, t...)
+	}
+	return out[:length]
+}
+
+func TestHKDF(t *testing.T) {
+	secret := []byte("input key material")
+	info := []byte("context info")
+	for _, salt := range [][]byte{nil, []byte("salt"), make([]byte, 100)} {
+		for _, n := range []int{0, 1, 31, 32, 33, 64, 100, 255 * Size} {
+			out, err := HKDF(secret, salt, info, n)
+			if err != nil {
+				t.Fatalf("%d: unexpected error: %v", n, err)
+			}
+			if exp := refHKDF(secret, salt, info, n); !bytes.Equal(out, exp) {
+				t.Errorf("%d: expected %x, got %x", n, exp, out)
+			}
+		}
+	}
+	if _, err := HKDF(secret, nil, info, 255*Size+1); err == nil {
+		t.Errorf("expected error for too large output length")
+	}
+}
+
+func TestIsSalted(t *testing.T) {
+	if New().(*Digest).IsSalted() {
+		t.Errorf("New: expected unsalted digest")
+	}
+	if !NewSalt([]byte("1234567890123456")).(*Digest).IsSalted() {
+		t.Errorf("NewSalt: expected salted digest")
+	}
+	if !New224Salt([]byte("1234567890123456")).(*Digest).IsSalted() {
+		t.Errorf("New224Salt: expected salted digest")
+	}
+	if NewSalt(make([]byte, 16)).(*Digest).IsSalted() {
+		t.Errorf("NewSalt with zero salt: expected unsalted digest")
+	}
+}
+
+// cancelReader returns zero bytes, calling cancel after n reads.
+type cancelReader struct {
+	n      int
+	cancel func()
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	if r.n--; r.n == 0 {
+		r.cancel()
+	}
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestSumContext256(t *testing.T) {
+	for i, v := range vectors256 {
+		sum, err := SumContext256(context.Background(), strings.NewReader(v.in))
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if res := fmt.Sprintf("%x", sum); res != v.out {
+			t.Errorf("%d: expected %q, got %q", i, v.out, res)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := SumContext256(ctx, &cancelReader{n: 3, cancel: cancel})
+	if err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) { return 0, errors.New("write error") }
+
+func TestSumCopy(t *testing.T) {
+	for i, v := range vectors256 {
+		var buf bytes.Buffer
+		sum, n, err := SumCopy(&buf, strings.NewReader(v.in))
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if n != int64(len(v.in)) || buf.String() != v.in {
+			t.Errorf("%d: copied data differs", i)
+		}
+		if res := fmt.Sprintf("%x", sum); res != v.out {
+			t.Errorf("%d: expected %q, got %q", i, v.out, res)
+		}
+	}
+	if _, _, err := SumCopy(errWriter{}, strings.NewReader("data")); err == nil {
+		t.Errorf("expected write error")
+	}
+}
+
+func TestSum256v(t *testing.T) {
+	b := make([]byte, 200)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	for _, split := range [][2]int{{0, 0}, {1, 2}, {55, 64}, {63, 130}, {64, 128}, {100, 200}} {
+		x, y, z := b[:split[0]], b[split[0]:split[1]], b[split[1]:]
+		joined := append(append(append([]byte{}, x...), y...), z...)
+		if Sum256v(x, y, z) != Sum256(joined) {
+			t.Errorf("%v: Sum256v differs from Sum256 of concatenation", split)
+		}
+	}
+	if Sum256v() != Sum256(nil) {
+		t.Errorf("Sum256v with no chunks differs from Sum256 of empty data")
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+	bad := make([]katVector, len(selfTestVectors))
+	copy(bad, selfTestVectors)
+	bad[3].out = append([]byte{}, bad[3].out...)
+	bad[3].out[0] ^= 1
+	if err := selfTest(bad); err == nil {
+		t.Errorf("expected self-test failure for corrupted vector")
+	}
+
+	saved := x8impl.Load()
+	defer x8impl.Store(saved)
+	for _, impl := range x8Implementations {
+		x8impl.Store(impl)
+		if err := SelfTest(); err != nil {
+			t.Errorf("%s: %v", impl.name, err)
+		}
+	}
+	x8impl.Store(&x8Implementation{"broken", func(in *[8][]byte) (out [8]Hash256) {
+		out = sum256x8Generic(in)
+		out[7][0] ^= 1
+		return
+	}})
+	if err := SelfTest(); err == nil {
+		t.Errorf("expected self-test failure for broken Sum256x8")
+	}
+}
+
+func TestSumTruncated(t *testing.T) {
+	for i, v := range vectors256salt {
+		h := NewSalt([]byte(v.salt))
+		h.Write([]byte(v.in))
+		full := h.Sum(nil)
+		for _, n := range []int{1, 16, 20, 31, 32} {
+			out, err := SumTruncated([]byte(v.in), []byte(v.salt), n)
+			if err != nil {
+				t.Fatalf("%d: unexpected error: %v", i, err)
+			}
+			if !bytes.Equal(out, full[:n]) {
+				t.Errorf("%d: expected %x, got %x", i, full[:n], out)
+			}
+		}
+	}
+	salt := []byte("1234567890123456")
+	for _, n := range []int{-1, 0, 33} {
+		if _, err := SumTruncated(nil, salt, n); err == nil {
+			t.Errorf("expected error for output length %d", n)
+		}
+	}
+	if _, err := SumTruncated(nil, salt[:8], 20); !errors.Is(err, ErrSaltLength) {
+		t.Errorf("expected %v, got %v", ErrSaltLength, err)
+	}
+}
+
+func TestSetSalt(t *testing.T) {
+	for i, v := range vectors256salt {
+		h := New().(*Digest)
+		if err := h.SetSalt([]byte(v.salt)); err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		h.Write([]byte(v.in))
+		res := fmt.Sprintf("%x", h.Sum(nil))
+		if res != v.out {
+			t.Errorf("%d: expected %q, got %q", i, v.out, res)
+		}
+	}
+	if err := New().(*Digest).SetSalt(make([]byte, 15)); !errors.Is(err, ErrSaltLength) {
+		t.Errorf("expected %v, got %v", ErrSaltLength, err)
+	}
+
+	// Constructors panic with ErrSaltLength.
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrSaltLength) {
+			t.Errorf("expected panic with %v, got %v", ErrSaltLength, err)
+		}
+	}()
+	New224Salt(make([]byte, 17))
+}
+
+// testChunkings checks that hashing msg with every possible split into
+// 1, 2 and 3 writes produces the same checksum.
+func testChunkings(t *testing.T, hashfunc func() hash.Hash, msg []byte) {
+	h := hashfunc()
+	h.Write(msg)
+	want := h.Sum(nil)
+	for i := 0; i <= len(msg); i++ {
+		for j := i; j <= len(msg); j++ {
+			h.Reset()
+			h.Write(msg[:i])
+			h.Write(msg[i:j])
+			h.Write(msg[j:])
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("split %d/%d of %d bytes: expected %x, got %x", i, j, len(msg), want, got)
+			}
+		}
+	}
+}
+
+func TestChunkings(t *testing.T) {
+	for _, v := range vectors256 {
+		testChunkings(t, New, []byte(v.in))
+	}
+	for _, v := range vectors224 {
+		testChunkings(t, New224, []byte(v.in))
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	h := New().(*Digest)
+	h.Write(make([]byte, 200))
+	if n := h.Blocks(); n != 3 {
+		t.Errorf("expected 3 blocks, got %d", n)
+	}
+	h.Sum(nil)
+	if n := h.Blocks(); n != 3 {
+		t.Errorf("after Sum: expected 3 blocks, got %d", n)
+	}
+	h.Write(make([]byte, 56))
+	if n := h.Blocks(); n != 4 {
+		t.Errorf("expected 4 blocks, got %d", n)
+	}
+	h.Reset()
+	if n := h.Blocks(); n != 0 {
+		t.Errorf("after Reset: expected 0 blocks, got %d", n)
+	}
+}
+
+func TestBlockTweak(t *testing.T) {
+	msg := make([]byte, 300)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	want := Sum256(msg)
+
+	h := New().(*Digest)
+	h.SetBlockTweak(func(uint64) [4]uint32 { return [4]uint32{} })
+	h.Write(msg)
+	if !bytes.Equal(h.Sum(nil), want[:]) {
+		t.Errorf("zero tweak: result differs from standard checksum")
+	}
+
+	counting := func(block uint64) [4]uint32 {
+		return [4]uint32{uint32(block >> 32), uint32(block), 0, 0}
+	}
+	h1 := New().(*Digest)
+	h1.SetBlockTweak(counting)
+	h1.Write(msg)
+	sum1 := h1.Sum(nil)
+	if bytes.Equal(sum1, want[:]) {
+		t.Errorf("counting tweak: result equals standard checksum")
+	}
+	h2 := New().(*Digest)
+	h2.SetBlockTweak(counting)
+	h2.Write(msg[:70])
+	h2.Write(msg[70:])
+	if sum2 := h2.Sum(nil); !bytes.Equal(sum1, sum2) {
+		t.Errorf("counting tweak: expected %x, got %x", sum1, sum2)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	const (
+		empty256 = "716f6e863f744b9ac22c97ec7b76ea5f5908bc5b2f67c61510bfc4751384ea7a"
+		empty224 = "7dc5313b1c04512a174bd6503b89607aecbee0903d40a8a569c94eed"
+	)
+	for _, v := range []struct {
+		hashfunc func() hash.Hash
+		out      string
+	}{{New, empty256}, {New224, empty224}} {
+		h := v.hashfunc()
+		for i := 0; i < 3; i++ {
+			if res := fmt.Sprintf("%x", h.Sum(nil)); res != v.out {
+				t.Errorf("Sum %d: expected %q, got %q", i, v.out, res)
+			}
+		}
+		h.Write(nil)
+		h.Write([]byte{})
+		if res := fmt.Sprintf("%x", h.Sum(nil)); res != v.out {
+			t.Errorf("after empty writes: expected %q, got %q", v.out, res)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	msg := make([]byte, 200)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	salt := []byte("1234567890123456")
+	for _, hashfunc := range []func() hash.Hash{New, New224, func() hash.Hash { return NewSalt(salt) }} {
+		for _, n := range []int{0, 1, 63, 64, 65, 130} {
+			h := hashfunc()
+			h.Write(msg)
+			want := h.Sum(nil)
+
+			h1 := hashfunc()
+			h1.Write(msg[:n])
+			prefix := []byte("prefix")
+			state, err := h1.(*Digest).AppendBinary(prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(state, []byte("prefix")) {
+				t.Fatalf("AppendBinary didn't preserve prefix")
+			}
+			m, _ := h1.(*Digest).MarshalBinary()
+			if !bytes.Equal(m, state[len(prefix):]) {
+				t.Fatalf("%d: MarshalBinary differs from AppendBinary", n)
+			}
+
+			h2 := New().(*Digest)
+			if err := h2.UnmarshalBinary(state[len(prefix):]); err != nil {
+				t.Fatal(err)
+			}
+			h2.Write(msg[n:])
+			if got := h2.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%d: expected %x, got %x", n, want, got)
+			}
+		}
+	}
+	if err := New().(*Digest).UnmarshalBinary([]byte("blk\x03")); err == nil {
+		t.Errorf("expected error for short state")
+	}
+	if err := New().(*Digest).UnmarshalBinary(make([]byte, marshaledSize)); err == nil {
+		t.Errorf("expected error for bad identifier")
+	}
+
+	// A full buffer is valid only with a partial last byte.
+	h := New().(*Digest)
+	h.writeBits(msg, (BlockSize-1)*8+3)
+	want := h.Sum(nil)
+	state, _ := h.MarshalBinary()
+	h2 := New().(*Digest)
+	if err := h2.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	if got := h2.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("partial byte: expected %x, got %x", want, got)
+	}
+
+	for _, v := range []struct {
+		nx, nbits byte
+		name      string
+	}{
+		{0, 7, "partial byte with empty buffer"},
+		{3, 8, "nbits out of range"},
+		{BlockSize, 0, "full buffer"},
+		{BlockSize + 1, 0, "nx out of range"},
+		{255, 3, "nx out of range"},
+	} {
+		state, _ := New().(*Digest).MarshalBinary()
+		state[marshaledSize-10], state[marshaledSize-9] = v.nx, v.nbits
+		if err := New().(*Digest).UnmarshalBinary(state); err == nil {
+			t.Errorf("%s (nx=%d, nbits=%d): expected error", v.name, v.nx, v.nbits)
+		}
+	}
+
+	h = New().(*Digest)
+	state, _ = h.MarshalBinary()
+	h.Close()
+	if err := h.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	h.Write(msg)
+	if got, want := h.Sum(nil), Sum256(msg); !bytes.Equal(got, want[:]) {
+		t.Errorf("after Close: expected %x, got %x", want, got)
+	}
+}
+
+func TestSum256Mmap(t *testing.T) {
+	dir := t.TempDir()
+	for _, n := range []int{0, 1, 64, 1000, 100000} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("file%d", n))
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		sum, err := Sum256Mmap(path)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", n, err)
+		}
+		want, err := SumFile256(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum != want || sum != Sum256(data) {
+			t.Errorf("%d: mmap checksum differs from streaming", n)
+		}
+	}
+	if _, err := Sum256Mmap(filepath.Join(dir, "nonexistent")); err == nil {
+		t.Errorf("expected error for nonexistent file")
+	}
+}
+
+func TestSaltFromCounter(t *testing.T) {
+	base := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	salt := SaltFromCounter(base, 0x0102030405060708)
+	want := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 1, 2, 3, 4, 5, 6, 7, 8}
+	if salt != want {
+		t.Errorf("expected %x, got %x", want, salt)
+	}
+	if SaltFromCounter(base, 42) != SaltFromCounter(base, 42) {
+		t.Errorf("salt is not deterministic")
+	}
+	seen := make(map[[16]byte]bool)
+	for i := uint64(0); i < 1000; i++ {
+		s := SaltFromCounter(base, i)
+		if seen[s] {
+			t.Fatalf("duplicate salt for counter %d", i)
+		}
+		seen[s] = true
+	}
+}
+
+func TestSumReset(t *testing.T) {
+	for i, v := range vectors256salt {
+		h := NewSalt([]byte(v.salt)).(*Digest)
+		for j := 0; j < 2; j++ {
+			h.Write([]byte(v.in))
+			res := fmt.Sprintf("%x", h.SumReset(nil))
+			if res != v.out {
+				t.Errorf("%d(%d): expected %q, got %q", i, j, v.out, res)
+			}
+		}
+	}
+	h := New224().(*Digest)
+	for i, v := range vectors224 {
+		h.Write([]byte(v.in))
+		res := fmt.Sprintf("%x", h.SumReset(nil))
+		if res != v.out {
+			t.Errorf("%d: expected %q, got %q", i, v.out, res)
+		}
+	}
+}
+
+func TestBlockWrite(t *testing.T) {
+	msg := make([]byte, 4*BlockSize+10)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	want := Sum256(msg)
+	h := New().(*Digest)
+	if err := h.BlockWrite(msg[:BlockSize]); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.BlockWrite(msg[BlockSize : 4*BlockSize]); err != nil {
+		t.Fatal(err)
+	}
+	h.Write(msg[4*BlockSize:])
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+	if err := h.BlockWrite(msg[:BlockSize]); err == nil {
+		t.Errorf("expected error with buffered data")
+	}
+	if err := New().(*Digest).BlockWrite(msg[:BlockSize+1]); err == nil {
+		t.Errorf("expected error for misaligned input")
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	for _, vectors := range [][]blakeVector{vectors256, vectors224} {
+		for i, v := range vectors {
+			expected, _ := hex.DecodeString(v.out)
+			m := NewMatcher(expected)
+			io.Copy(m, iotest.OneByteReader(strings.NewReader(v.in)))
+			if !m.Verify() {
+				t.Errorf("%d: expected match", i)
+			}
+			m.Write([]byte{0})
+			if m.Verify() {
+				t.Errorf("%d: expected mismatch for tampered stream", i)
+			}
+			expected[0] ^= 1
+			m = NewMatcher(expected)
+			m.Write([]byte(v.in))
+			if m.Verify() {
+				t.Errorf("%d: expected mismatch for wrong checksum", i)
+			}
+		}
+	}
+}
+
+func TestCounterCarry(t *testing.T) {
+	h := New().(*Digest)
+	h.t = 1<<32 - 512
+	h.Write(make([]byte, 2*BlockSize))
+	if h.t != 1<<32+512 {
+		t.Errorf("expected counter %x, got %x", uint64(1<<32+512), h.t)
+	}
+}
+
+func TestSumPooled(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				h := New().(*Digest)
+				h.Write([]byte{byte(g), byte(i)})
+				want := h.Sum(nil)
+				sum1, release1 := h.SumPooled()
+				h.Write([]byte{0})
+				sum2, release2 := h.SumPooled()
+				if &sum1[0] == &sum2[0] {
+					t.Errorf("pooled buffers are aliased")
+				}
+				if !bytes.Equal(sum1, want) {
+					t.Errorf("expected %x, got %x", want, sum1)
+				}
+				release1()
+				release2()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	h := New224().(*Digest)
+	sum, release := h.SumPooled()
+	if len(sum) != Size224 {
+		t.Errorf("expected %d bytes, got %d", Size224, len(sum))
+	}
+	release()
+}
+
+func TestSumFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, n := range []int{0, 1, 55, 64, 4097, 1<<20 + 13} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("file%d", n))
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		sum256, err := SumFile256(path)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", n, err)
+		}
+		if sum256 != Sum256(data) {
+			t.Errorf("%d: SumFile256 differs from Sum256", n)
+		}
+		sum224, err := SumFile224(path)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", n, err)
+		}
+		if sum224 != Sum224(data) {
+			t.Errorf("%d: SumFile224 differs from Sum224", n)
+		}
+	}
+	if _, err := SumFile256(filepath.Join(dir, "nonexistent")); err == nil {
+		t.Errorf("expected error for nonexistent file")
+	}
+	if _, err := SumFile224(dir); err == nil {
+		t.Errorf("expected error for directory")
+	}
+}
+
+func TestDomainSeparated(t *testing.T) {
+	msg := []byte("message")
+	sum := func(domain []byte) []byte {
+		h := NewDomainSeparated(domain)
+		h.Write(msg)
+		return h.Sum(nil)
+	}
+	a := sum([]byte("domain A"))
+	if !bytes.Equal(a, sum([]byte("domain A"))) {
+		t.Errorf("same domain produced different checksums")
+	}
+	for _, d := range [][]byte{nil, []byte("domain B"), []byte("domain A\x00"), make([]byte, 100)} {
+		if bytes.Equal(a, sum(d)) {
+			t.Errorf("domain %q: expected different checksum", d)
+		}
+	}
+
+	// Check prefix encoding and Reset.
+	prefix := make([]byte, BlockSize)
+	prefix[7] = 8
+	copy(prefix[8:], "domain A")
+	want := Sum256(append(prefix, msg...))
+	if !bytes.Equal(a, want[:]) {
+		t.Errorf("expected %x, got %x", want, a)
+	}
+	h := NewDomainSeparated([]byte("domain A"))
+	h.Write([]byte("garbage"))
+	h.Reset()
+	h.Write(msg)
+	if got := h.Sum(nil); !bytes.Equal(got, a) {
+		t.Errorf("after Reset: expected %x, got %x", a, got)
+	}
+
+	// Every method that resets the state must keep the prefix.
+	for name, reset := range map[string]func(h hash.Hash) bool{
+		"Reset": func(h hash.Hash) bool { h.Reset(); return true },
+		"SumReset": func(h hash.Hash) bool {
+			r, ok := h.(interface{ SumReset([]byte) []byte })
+			if ok {
+				r.SumReset(nil)
+			}
+			return ok
+		},
+		"ResetIV": func(h hash.Hash) bool {
+			r, ok := h.(interface{ ResetIV([8]uint32) })
+			if ok {
+				r.ResetIV(iv256)
+			}
+			return ok
+		},
+		"RehashWithSalt": func(h hash.Hash) bool {
+			r, ok := h.(interface {
+				RehashWithSalt(salt, data []byte) ([]byte, error)
+			})
+			if ok {
+				r.RehashWithSalt(make([]byte, 16), nil)
+			}
+			return ok
+		},
+	} {
+		h := NewDomainSeparated([]byte("domain A"))
+		h.Write([]byte("garbage"))
+		if !reset(h) {
+			continue
+		}
+		h.Write(msg)
+		if got := h.Sum(nil); !bytes.Equal(got, a) {
+			t.Errorf("after %s: expected %x, got %x", name, a, got)
+		}
+	}
+}
+
+func TestWriteTooLong(t *testing.T) {
+	h := New().(*Digest)
+	h.t = (maxLength - 100) &^ (BlockSize - 1) << 3
+	rem := int(maxLength - h.t>>3)
+	n, err := h.Write(make([]byte, rem-10))
+	if n != rem-10 || err != nil {
+		t.Fatalf("expected %d, nil; got %d, %v", rem-10, n, err)
+	}
+	n, err = h.Write(make([]byte, 20))
+	if n != 10 || !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected 10, %v; got %d, %v", ErrTooLong, n, err)
+	}
+	n, err = h.Write([]byte{0})
+	if n != 0 || !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected 0, %v; got %d, %v", ErrTooLong, n, err)
+	}
+	if n, err = h.Write(nil); n != 0 || err != nil {
+		t.Errorf("expected 0, nil; got %d, %v", n, err)
+	}
+}
+
+func TestBase64Base32(t *testing.T) {
+	for _, hashfunc := range []func() hash.Hash{New, New224} {
+		h := hashfunc().(*Digest)
+		h.Write([]byte("BLAKE"))
+		want := h.Sum(nil)
+		b64, err := base64.RawURLEncoding.DecodeString(h.Base64URL())
+		if err != nil || !bytes.Equal(b64, want) {
+			t.Errorf("Base64URL: expected %x, got %x (%v)", want, b64, err)
+		}
+		b32, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(h.Base32())
+		if err != nil || !bytes.Equal(b32, want) {
+			t.Errorf("Base32: expected %x, got %x (%v)", want, b32, err)
+		}
+		if strings.ContainsRune(h.Base64URL()+h.Base32(), '=') {
+			t.Errorf("unexpected padding")
+		}
+	}
+}
+
+func TestSumThen(t *testing.T) {
+	for _, trailer := range [][]byte{nil, []byte("trailer"), make([]byte, 100)} {
+		h := NewSalt([]byte("1234567890123456")).(*Digest)
+		h.Write([]byte("message"))
+		want := Sum256(append(h.Sum(nil), trailer...))
+		if got := h.SumThen(trailer); !bytes.Equal(got, want[:]) {
+			t.Errorf("expected %x, got %x", want, got)
+		}
+
+		h = New224().(*Digest)
+		h.Write([]byte("message"))
+		want224 := Sum224(append(h.Sum(nil), trailer...))
+		if got := h.SumThen(trailer); !bytes.Equal(got, want224[:]) {
+			t.Errorf("224: expected %x, got %x", want224, got)
+		}
+	}
+}
+
+type katEntry struct {
+	bits int    // message length in bits
+	msg  []byte // message, last byte holds trailing bits in high positions
+	md   []byte // expected checksum
+}
+
+// readKAT parses a file in SHA-3 competition KAT format.
+func readKAT(path string) ([]katEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []katEntry
+	var e katEntry
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		key, value, ok := strings.Cut(s.Text(), " = ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Len":
+			if e.bits, err = strconv.Atoi(value); err != nil {
+				return nil, err
+			}
+		case "Msg":
+			if e.msg, err = hex.DecodeString(value); err != nil {
+				return nil, err
+			}
+			e.msg = e.msg[:(e.bits+7)/8]
+		case "MD":
+			if e.md, err = hex.DecodeString(value); err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+			e = katEntry{}
+		}
+	}
+	return entries, s.Err()
+}
+
+func TestKAT(t *testing.T) {
+	// ShortMsgKAT_256.txt is the official file from the BLAKE submission
+	// to the SHA-3 competition. It is not checked in yet; copy it into
+	// testdata without changes to run it. ref_kat_256.txt was computed by
+	// testdata/blake_ref.py.
+	for _, name := range []string{"ShortMsgKAT_256.txt", "ref_kat_256.txt"} {
+		t.Run(name, func(t *testing.T) {
+			entries, err := readKAT(filepath.Join("testdata", name))
+			if os.IsNotExist(err) && name == "ShortMsgKAT_256.txt" {
+				t.Skip("official KAT file is missing")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) == 0 {
+				t.Fatal("no KAT entries")
+			}
+			for _, e := range entries {
+				h := New().(*Digest)
+				if err := h.writeBits(e.msg, e.bits); err != nil {
+					t.Fatalf("Len = %d: %v", e.bits, err)
+				}
+				if got := h.Sum(nil); !bytes.Equal(got, e.md) {
+					t.Errorf("Len = %d: expected %X, got %X", e.bits, e.md, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteBits(t *testing.T) {
+	msg := make([]byte, 130)
+	for i := range msg {
+		msg[i] = byte(i*13 + 1)
+	}
+	// Byte-aligned bit strings hash as bytes.
+	for _, n := range []int{0, 1, 55, 56, 64, 130} {
+		h := New().(*Digest)
+		h.Write(msg[:n/2])
+		if err := h.writeBits(msg[n/2:n], (n-n/2)*8); err != nil {
+			t.Fatal(err)
+		}
+		want := Sum256(msg[:n])
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("%d: expected %x, got %x", n, want, got)
+		}
+	}
+	// Unused low bits of the last byte are ignored.
+	h1 := New().(*Digest)
+	h1.writeBits([]byte{0xff, 0xf0}, 12)
+	h2 := New().(*Digest)
+	h2.writeBits([]byte{0xff, 0xff}, 12)
+	if !bytes.Equal(h1.Sum(nil), h2.Sum(nil)) {
+		t.Errorf("unused bits affect checksum")
+	}
+	h3 := New().(*Digest)
+	h3.writeBits([]byte{0xff, 0xf0}, 13)
+	if bytes.Equal(h1.Sum(nil), h3.Sum(nil)) {
+		t.Errorf("different bit lengths produce the same checksum")
+	}
+	// No writes after a partial byte.
+	if _, err := h1.Write([]byte{1}); err == nil {
+		t.Errorf("Write: expected error after partial byte")
+	}
+	if err := h1.writeBits([]byte{1}, 1); err == nil {
+		t.Errorf("writeBits: expected error after partial byte")
+	}
+	if err := h1.writeBits([]byte{1}, 9); err == nil {
+		t.Errorf("expected error for nbits larger than input")
+	}
+	// State with a partial byte survives marshaling.
+	h4 := New().(*Digest)
+	h4.writeBits(msg, 64*8-3)
+	state, _ := h4.MarshalBinary()
+	h5 := New().(*Digest)
+	if err := h5.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(h4.Sum(nil), h5.Sum(nil)) {
+		t.Errorf("unmarshaled state differs")
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	header := []byte("header of some length that exceeds one block, to be compressed")
+	bodies := [][]byte{nil, []byte("body 1"), make([]byte, 200), []byte("body 3")}
+	h := NewSalt([]byte("1234567890123456")).(*Digest)
+	h.Write(header)
+	c := h.Checkpoint()
+	for i, body := range bodies {
+		h.Rewind(c)
+		h.Write(body)
+		got := h.Sum(nil)
+
+		ref := NewSalt([]byte("1234567890123456"))
+		ref.Write(header)
+		ref.Write(body)
+		if want := ref.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("%d: expected %x, got %x", i, want, got)
+		}
+	}
+}
+
+func TestSumParallel256(t *testing.T) {
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, chunkSize := range []int{1000, 4096, 65536, 200000} {
+		var chunkSums []byte
+		for i := 0; i < len(data); i += chunkSize {
+			sum := Sum256(data[i:min(i+chunkSize, len(data))])
+			chunkSums = append(chunkSums, sum[:]...)
+		}
+		want := Sum256(chunkSums)
+		for _, workers := range []int{1, 2, 3, 8} {
+			got, err := SumParallel256(bytes.NewReader(data), chunkSize, workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("chunk size %d, %d workers: expected %x, got %x", chunkSize, workers, want, got)
+			}
+		}
+	}
+	if got, _ := SumParallel256(bytes.NewReader(nil), 10, 2); got != Sum256(nil) {
+		t.Errorf("empty input: expected %x, got %x", Sum256(nil), got)
+	}
+	if _, err := SumParallel256(iotest.ErrReader(io.ErrClosedPipe), 10, 2); err != io.ErrClosedPipe {
+		t.Errorf("expected %v, got %v", io.ErrClosedPipe, err)
+	}
+	if _, err := SumParallel256(bytes.NewReader(data), 0, 2); err == nil {
+		t.Errorf("expected error for zero chunk size")
+	}
+}
+
+func TestGrow(t *testing.T) {
+	msg := make([]byte, 300)
+	h := New().(*Digest)
+	if err := h.Grow(len(msg)); err != nil {
+		t.Fatal(err)
+	}
+	h.Write(msg[:100])
+	h.Write(msg[100:])
+	want := Sum256(msg)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+	if err := h.Grow(-1); err == nil {
+		t.Errorf("expected error for negative length")
+	}
+	if strconv.IntSize == 64 {
+		var huge uint64 = maxLength + 1
+		if err := h.Grow(int(huge)); !errors.Is(err, ErrTooLong) {
+			t.Errorf("expected %v, got %v", ErrTooLong, err)
+		}
+	}
+}
+
+// TestRefVectors checks vectors computed by a separate Python
+// implementation written for these tests (see testdata/blake_ref.py).
+// They are not from a third-party implementation.
+func TestRefVectors(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "ref_vectors.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	n := 0
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 4 {
+			t.Fatalf("bad line %q", s.Text())
+		}
+		var salt, msg []byte
+		if fields[1] != "-" {
+			salt, _ = hex.DecodeString(fields[1])
+		}
+		if fields[2] != "-" {
+			msg, _ = hex.DecodeString(fields[2])
+		}
+		var h hash.Hash
+		switch {
+		case fields[0] == "224" && salt == nil:
+			h = New224()
+		case fields[0] == "224":
+			h = New224Salt(salt)
+		case salt == nil:
+			h = New()
+		default:
+			h = NewSalt(salt)
+		}
+		h.Write(msg)
+		if res := fmt.Sprintf("%x", h.Sum(nil)); res != fields[3] {
+			t.Errorf("BLAKE-%s, salt %s, %d bytes: expected %s, got %s", fields[0], fields[1], len(msg), fields[3], res)
+		}
+		n++
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Fatal("no vectors")
+	}
+}
+
+func TestNewLimited(t *testing.T) {
+	const limit = 100
+	msg := make([]byte, limit+1)
+	if h, err := NewLimited(limit); err != nil {
+		t.Fatal(err)
+	} else if _, ok := h.(interface{ BlockWrite([]byte) error }); ok {
+		t.Errorf("BlockWrite can bypass the limit")
+	}
+	for _, v := range []struct {
+		writes []int
+		err    bool
+	}{
+		{[]int{limit - 1}, false},
+		{[]int{limit}, false},
+		{[]int{50, 50}, false},
+		{[]int{limit + 1}, true},
+		{[]int{60, 41}, true},
+		{[]int{limit, 1}, true},
+	} {
+		h, err := NewLimited(limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var total int
+		var werr error
+		for _, n := range v.writes {
+			var nn int
+			nn, werr = h.Write(msg[:n])
+			total += nn
+			if werr != nil {
+				break
+			}
+		}
+		if v.err != errors.Is(werr, ErrLimitExceeded) {
+			t.Errorf("%v: unexpected error %v", v.writes, werr)
+		}
+		if total > limit {
+			t.Errorf("%v: absorbed %d bytes over limit", v.writes, total)
+		}
+		if !v.err {
+			want := Sum256(msg[:total])
+			if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+				t.Errorf("%v: expected %x, got %x", v.writes, want, got)
+			}
+		}
+		h.Reset()
+		if _, err := h.Write(msg[:limit]); err != nil {
+			t.Errorf("%v: after Reset: unexpected error %v", v.writes, err)
+		}
+	}
+	if _, err := NewLimited(-1); err == nil {
+		t.Errorf("expected error for negative limit")
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	h := New().(*Digest)
+	if r := h.Remaining(); r != maxLength {
+		t.Errorf("expected %d, got %d", uint64(maxLength), r)
+	}
+	h.Write(make([]byte, 100))
+	if r := h.Remaining(); r != maxLength-100 {
+		t.Errorf("expected %d, got %d", uint64(maxLength-100), r)
+	}
+
+	h.Reset()
+	h.t = (maxLength - 100) &^ (BlockSize - 1) << 3
+	r := h.Remaining()
+	h.Write(make([]byte, r-1))
+	if r := h.Remaining(); r != 1 {
+		t.Errorf("expected 1, got %d", r)
+	}
+	h.Write([]byte{0, 0})
+	if r := h.Remaining(); r != 0 {
+		t.Errorf("expected 0, got %d", r)
+	}
+
+	h.Reset()
+	h.writeBits([]byte{0xff}, 3)
+	if r := h.Remaining(); r != 0 {
+		t.Errorf("after partial byte: expected 0, got %d", r)
+	}
+}
+
+func TestStructHasher(t *testing.T) {
+	s := NewStructHasher()
+	s.AddUint32(0x01020304)
+	s.AddString("ab")
+	s.AddUint64(0x05060708090a0b0c)
+	s.AddBytes([]byte("c"))
+	got := s.Sum(nil)
+
+	want := Sum256([]byte("\x01\x02\x03\x04" +
+		"\x00\x00\x00\x00\x00\x00\x00\x02ab" +
+		"\x05\x06\x07\x08\x09\x0a\x0b\x0c" +
+		"\x00\x00\x00\x00\x00\x00\x00\x01c"))
+	if !bytes.Equal(got, want[:]) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+
+	s2 := StructHasher{NewDigest()}
+	s2.WriteUint32(0x01020304)
+	s2.AddBytes([]byte("ab"))
+	s2.WriteUint64(0x05060708090a0b0c)
+	s2.AddString("c")
+	if !bytes.Equal(got, s2.Sum(nil)) {
+		t.Errorf("string and bytes or Add and Write encodings differ")
+	}
+
+	s3 := NewStructHasher()
+	s3.AddString("a")
+	s3.AddString("bc")
+	s4 := NewStructHasher()
+	s4.AddString("ab")
+	s4.AddString("c")
+	if bytes.Equal(s3.Sum(nil), s4.Sum(nil)) {
+		t.Errorf("different strings produce the same checksum")
+	}
+}
+
+func TestSizeBitsName(t *testing.T) {
+	h := New().(*Digest)
+	if h.SizeBits() != 256 || h.Name() != "BLAKE-256" {
+		t.Errorf("New: got %d, %q", h.SizeBits(), h.Name())
+	}
+	h = New224().(*Digest)
+	if h.SizeBits() != 224 || h.Name() != "BLAKE-224" {
+		t.Errorf("New224: got %d, %q", h.SizeBits(), h.Name())
+	}
+}
+
+func FuzzWrite(f *testing.F) {
+	// Lengths around the padding branches in checkSum: 55 bytes leave room
+	// for exactly one padding byte, 56..63 need an extra block, and
+	// multiples of 64 end with a block without message bits.
+	for _, n := range []int{0, 1, 55, 56, 63, 64, 65, 119, 120, 128} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		f.Add(data, uint(n/2), false)
+		f.Add(data, uint(1), true)
+	}
+	salt := []byte("1234567890123456")
+	f.Fuzz(func(t *testing.T, data []byte, split uint, salted bool) {
+		var h1, h2 hash.Hash
+		if salted {
+			h1, h2 = NewSalt(salt), NewSalt(salt)
+		} else {
+			h1, h2 = New(), New()
+		}
+		k := int(split % uint(len(data)+1))
+		h1.Write(data)
+		h2.Write(data[:k])
+		h2.Write(data[k:])
+		sum := h1.Sum(nil)
+		if !bytes.Equal(sum, h2.Sum(nil)) {
+			t.Errorf("split at %d: checksums differ", k)
+		}
+		if want := Sum256(data); !salted && !bytes.Equal(sum, want[:]) {
+			t.Errorf("expected %x, got %x", want, sum)
+		}
+	})
+}
+
+func TestAbsorb(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i * 3)
+	}
+	want := Sum256(data)
+	for _, r := range []io.Reader{
+		bytes.NewReader(data),
+		iotest.OneByteReader(bytes.NewReader(data)),
+		iotest.HalfReader(bytes.NewReader(data)),
+		iotest.DataErrReader(bytes.NewReader(data)),
+	} {
+		h := New().(*Digest)
+		n, err := h.Absorb(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(data)) {
+			t.Errorf("expected %d bytes, got %d", len(data), n)
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("expected %x, got %x", want, got)
+		}
+	}
+	h := New().(*Digest)
+	n, err := h.Absorb(io.MultiReader(bytes.NewReader(data[:100]), iotest.ErrReader(io.ErrUnexpectedEOF)))
+	if n != 100 || err != io.ErrUnexpectedEOF {
+		t.Errorf("expected 100, %v; got %d, %v", io.ErrUnexpectedEOF, n, err)
+	}
+}
+
+func TestNewSize(t *testing.T) {
+	for _, bits := range []int{224, 256} {
+		h, err := NewSize(bits)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", bits, err)
+		}
+		if h.Size()*8 != bits {
+			t.Errorf("%d: got size %d", bits, h.Size())
+		}
+	}
+	newTestVectors(t, func() hash.Hash { h, _ := NewSize(224); return h }, vectors224)
+	newTestVectors(t, func() hash.Hash { h, _ := NewSize(256); return h }, vectors256)
+	if _, err := NewSize(160); err == nil {
+		t.Errorf("expected error for 160 bits")
+	}
+}
+
+func TestMAC(t *testing.T) {
+	keys := [][]byte{nil, []byte("key"), make([]byte, BlockSize), make([]byte, 100)}
+	msg := []byte("The quick brown fox jumps over the lazy dog")
+	m := NewMACKeyed([]byte("initial key"))
+	m.Write([]byte("garbage"))
+	for i, key := range keys {
+		ref := hmac.New(New, key)
+		ref.Write(msg)
+		want := ref.Sum(nil)
+
+		m.Rekey(key)
+		m.Write(msg)
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("%d: expected %x, got %x", i, want, got)
+		}
+		fresh := NewMACKeyed(key)
+		fresh.Write(msg)
+		if got := fresh.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("%d: fresh: expected %x, got %x", i, want, got)
+		}
+		m.Reset()
+		m.Write(msg)
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("%d: after Reset: expected %x, got %x", i, want, got)
+		}
+	}
+	if n := testing.AllocsPerRun(10, func() { m.Rekey([]byte("another key")) }); n > 0 {
+		t.Errorf("Rekey allocated %v times", n)
+	}
+}
+
+func TestPutSum(t *testing.T) {
+	for _, hashfunc := range []func() hash.Hash{New, New224} {
+		h := hashfunc()
+		h.Write([]byte("BLAKE"))
+		want := h.Sum(nil)
+		frame := bytes.Repeat([]byte{0xee}, 40)
+		if n := h.(*Digest).PutSum(frame[4:]); n != len(want) {
+			t.Errorf("expected %d bytes, got %d", len(want), n)
+		}
+		if !bytes.Equal(frame[4:4+len(want)], want) || frame[3] != 0xee || frame[4+len(want)] != 0xee {
+			t.Errorf("expected %x in frame, got %x", want, frame)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for short destination")
+		}
+	}()
+	New().(*Digest).PutSum(make([]byte, Size-1))
+}
+
+func TestWriteAll(t *testing.T) {
+	for i, v := range vectors256 {
+		half := len(v.in) / 2
+		if res := NewDigest().WriteAll([]byte(v.in[:half]), []byte(v.in[half:])).SumHex(); res != v.out {
+			t.Errorf("%d: expected %q, got %q", i, v.out, res)
+		}
+	}
+	for i, v := range vectors224 {
+		if res := NewDigest224().WriteAll([]byte(v.in)).SumHex(); res != v.out {
+			t.Errorf("%d: expected %q, got %q", i, v.out, res)
+		}
+	}
+	if res := NewDigest().WriteAll().SumHex(); res != vectors256[2].out {
+		t.Errorf("expected %q, got %q", vectors256[2].out, res)
+	}
+}
+
+func TestWindowed(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, size := range []int{1, 13, 64, 100} {
+		w := NewWindowed(size)
+		if w.CurrentHash() != Sum256(nil) {
+			t.Errorf("%d: empty window checksum differs", size)
+		}
+		for i, b := range data {
+			w.Advance(b)
+			window := data[max(0, i+1-size) : i+1]
+			if !bytes.Equal(w.Window(nil), window) {
+				t.Fatalf("%d: window at %d differs", size, i)
+			}
+			if w.CurrentHash() != Sum256(window) {
+				t.Fatalf("%d: checksum at %d differs", size, i)
+			}
+		}
+	}
+}
+
+func TestAlgorithmName(t *testing.T) {
+	if name := AlgorithmName(); name != "BLAKE-256" || name != NewDigest().Name() {
+		t.Errorf("unexpected name %q", name)
+	}
+	if id := NewDigest().AlgorithmID(); id != "blake256" {
+		t.Errorf("expected %q, got %q", "blake256", id)
+	}
+	if id := NewDigest224().AlgorithmID(); id != "blake224" {
+		t.Errorf("expected %q, got %q", "blake224", id)
+	}
+}
+
+func TestAbsorbBuffered(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 5)
+	}
+	for _, bufSize := range []int{16, 64, 100, 4096} {
+		br := bufio.NewReaderSize(iotest.HalfReader(bytes.NewReader(data)), bufSize)
+		h := NewDigest()
+		if err := h.AbsorbBuffered(br, 10); err != nil {
+			t.Fatal(err)
+		}
+		if err := h.AbsorbBuffered(br, 900); err != nil {
+			t.Fatal(err)
+		}
+		want := Sum256(data[:910])
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("buffer size %d: expected %x, got %x", bufSize, want, got)
+		}
+		if rest, _ := io.ReadAll(br); !bytes.Equal(rest, data[910:]) {
+			t.Errorf("buffer size %d: wrong remaining data", bufSize)
+		}
+		br = bufio.NewReaderSize(bytes.NewReader(data), bufSize)
+		if err := NewDigest().AbsorbBuffered(br, len(data)+1); err != io.ErrUnexpectedEOF {
+			t.Errorf("buffer size %d: expected %v, got %v", bufSize, io.ErrUnexpectedEOF, err)
+		}
+	}
+}
+
+func TestSumFiles256(t *testing.T) {
+	dir := t.TempDir()
+	var all []byte
+	var paths []string
+	for i, n := range []int{100, 0, 1, 64, 5000} {
+		data := bytes.Repeat([]byte{byte(i + 1)}, n)
+		all = append(all, data...)
+		path := filepath.Join(dir, fmt.Sprintf("file%d", i))
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	joined := filepath.Join(dir, "joined")
+	if err := os.WriteFile(joined, all, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want, err := SumFile256(joined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := SumFiles256(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+	if got, _ := SumFiles256(nil); got != Sum256(nil) {
+		t.Errorf("no files: expected %x, got %x", Sum256(nil), got)
+	}
+	if _, err := SumFiles256([]string{paths[0], filepath.Join(dir, "nonexistent")}); err == nil {
+		t.Errorf("expected error for nonexistent file")
+	}
+}
+
+func TestStrict(t *testing.T) {
+	if !NewDigest().CanContinue() {
+		t.Errorf("expected CanContinue to return true")
+	}
+	h := NewStrict()
+	newTestVectors(t, NewStrict, vectors256)
+	h.Write([]byte("a"))
+	h.Sum(nil)
+	h.Sum(nil)
+	h.Reset()
+	h.Write([]byte("b")) // must not panic after Reset
+	h.Sum(nil)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic on Write after Sum")
+			}
+		}()
+		h.Write([]byte("c"))
+	}()
+
+	n := New()
+	n.Write([]byte("a"))
+	n.Sum(nil)
+	n.Write([]byte("b"))
+	want := Sum256([]byte("ab"))
+	if got := n.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("normal mode: expected %x, got %x", want, got)
+	}
+}
+
+func TestSum256x8(t *testing.T) {
+	if !hasAVX512 {
+		t.Log("AVX-512 is not supported, testing fallback only")
+	}
+	for _, n := range []int{0, 1, 55, 56, 63, 64, 65, 119, 120, 128, 1000} {
+		var in [8][]byte
+		for j := range in {
+			in[j] = make([]byte, n)
+			for i := range in[j] {
+				in[j][i] = byte(i*7 + j*13)
+			}
+		}
+		out := Sum256x8(in)
+		for j := range in {
+			if want := Sum256(in[j]); out[j] != want {
+				t.Errorf("%d bytes, lane %d: expected %x, got %x", n, j, want, out[j])
+			}
+		}
+	}
+	// Different lengths.
+	var in [8][]byte
+	for j := range in {
+		in[j] = make([]byte, j*30)
+	}
+	out := Sum256x8(in)
+	for j := range in {
+		if want := Sum256(in[j]); out[j] != want {
+			t.Errorf("lane %d: expected %x, got %x", j, want, out[j])
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	p := make([]byte, BlockSize)
+	for i := range p {
+		p[i] = byte(i * 3)
+	}
+	var m [16]uint32
+	for i := range m {
+		m[i] = uint32(p[i*4])<<24 | uint32(p[i*4+1])<<16 | uint32(p[i*4+2])<<8 | uint32(p[i*4+3])
+	}
+	// block has the rounds unrolled; composing 14 Round calls
+	// must give the same compression.
+	for _, salt := range [][]byte{make([]byte, 16), []byte("0123456789abcdef")} {
+		d := NewSalt(salt).(*Digest)
+		block(d, p)
+
+		s := d.s
+		v := [16]uint32{
+			iv256[0], iv256[1], iv256[2], iv256[3], iv256[4], iv256[5], iv256[6], iv256[7],
+			cst0 ^ s[0], cst1 ^ s[1], cst2 ^ s[2], cst3 ^ s[3],
+			cst4 ^ 512, cst5 ^ 512, cst6, cst7,
+		}
+		for r := 0; r < 14; r++ {
+			Round(&v, &m, r)
+		}
+		for i := range d.h {
+			if h := iv256[i] ^ v[i] ^ v[i+8] ^ s[i%4]; d.h[i] != h {
+				t.Errorf("salt %q: h[%d]: expected %08x, got %08x", salt, i, d.h[i], h)
+			}
+		}
+	}
+
+	var v [16]uint32
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for round 14")
+		}
+	}()
+	Round(&v, &m, 14)
+}
+
+func FuzzRound(f *testing.F) {
+	f.Add(make([]byte, BlockSize), make([]byte, 16), uint64(0))
+	f.Add(bytes.Repeat([]byte{0xff}, BlockSize), []byte("0123456789abcdef"), uint64(1<<32-512))
+	f.Fuzz(func(t *testing.T, p, salt []byte, counter uint64) {
+		if len(p) < BlockSize || len(salt) < 16 {
+			return
+		}
+		p, salt = p[:BlockSize], salt[:16]
+		d := NewSalt(salt).(*Digest)
+		d.t = counter
+		block(d, p)
+
+		var m [16]uint32
+		for i := range m {
+			m[i] = uint32(p[i*4])<<24 | uint32(p[i*4+1])<<16 | uint32(p[i*4+2])<<8 | uint32(p[i*4+3])
+		}
+		s, c := d.s, counter+512
+		v := [16]uint32{
+			iv256[0], iv256[1], iv256[2], iv256[3], iv256[4], iv256[5], iv256[6], iv256[7],
+			cst0 ^ s[0], cst1 ^ s[1], cst2 ^ s[2], cst3 ^ s[3],
+			cst4 ^ uint32(c), cst5 ^ uint32(c), cst6 ^ uint32(c>>32), cst7 ^ uint32(c>>32),
+		}
+		for r := 0; r < 14; r++ {
+			Round(&v, &m, r)
+		}
+		for i := range d.h {
+			if h := iv256[i] ^ v[i] ^ v[i+8] ^ s[i%4]; d.h[i] != h {
+				t.Fatalf("h[%d]: block gives %08x, Round gives %08x", i, d.h[i], h)
+			}
+		}
+	})
+}
+
+func TestNewSalt2(t *testing.T) {
+	salt, context := []byte("01234567"), []byte("89abcdef")
+	h, err := NewSalt2(salt, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := NewSalt([]byte("0123456789abcdef"))
+	h.Write([]byte("message"))
+	ref.Write([]byte("message"))
+	if got, want := h.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+	if _, err := NewSalt2(salt, context[:7]); err == nil {
+		t.Errorf("expected error for short context")
+	}
+	if _, err := NewSalt2(nil, context); err == nil {
+		t.Errorf("expected error for missing salt")
+	}
+}
+
+func TestSumByteOrder(t *testing.T) {
+	// Chain values after hashing a single zero byte.
+	tests := []struct {
+		d     *Digest
+		words []uint32
+	}{
+		{NewDigest(), []uint32{0x0ce8d4ef, 0x4dd7cd8d, 0x62dfded9, 0xd4edb0a7, 0x74ae6a41, 0x929a74da, 0x23109e8f, 0x11139c87}},
+		{NewDigest224(), []uint32{0x4504cb03, 0x14fb2a4f, 0x7a692e69, 0x6e487912, 0xfe3f2468, 0xfe312c73, 0xa5278ec5}},
+	}
+	for _, tt := range tests {
+		tt.d.Write([]byte{0})
+		sum := tt.d.Sum(nil)
+		d := *tt.d
+		d.checkSum()
+		if len(sum) != 4*len(tt.words) {
+			t.Fatalf("%d: expected %d bytes, got %d", tt.d.hashSize, 4*len(tt.words), len(sum))
+		}
+		for i, w := range tt.words {
+			if d.h[i] != w {
+				t.Errorf("%d: h[%d]: expected %08x, got %08x", tt.d.hashSize, i, w, d.h[i])
+			}
+			want := []byte{byte(w >> 24), byte(w >> 16), byte(w >> 8), byte(w)}
+			if got := sum[i*4 : i*4+4]; !bytes.Equal(got, want) {
+				t.Errorf("%d: word %d: expected bytes %x, got %x", tt.d.hashSize, i, want, got)
+			}
+		}
+	}
+}
+
+func TestSumMap256(t *testing.T) {
+	a := make(map[string][]byte)
+	a["x"] = []byte("1")
+	a["y"] = []byte("22")
+	a["z"] = nil
+	b := make(map[string][]byte)
+	b["z"] = nil
+	b["y"] = []byte("22")
+	b["x"] = []byte("1")
+	if SumMap256(a) != SumMap256(b) {
+		t.Errorf("same entries produce different checksums")
+	}
+
+	want := Sum256([]byte("\x00\x00\x00\x00\x00\x00\x00\x01x\x00\x00\x00\x00\x00\x00\x00\x011" +
+		"\x00\x00\x00\x00\x00\x00\x00\x01y\x00\x00\x00\x00\x00\x00\x00\x0222" +
+		"\x00\x00\x00\x00\x00\x00\x00\x01z\x00\x00\x00\x00\x00\x00\x00\x00"))
+	if got := SumMap256(a); got != want {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+
+	for _, m := range []map[string][]byte{
+		{"x": []byte("1"), "y": []byte("22")},
+		{"x": []byte("1"), "y": []byte("22"), "z": []byte{}, "w": nil},
+		{"x": []byte("12"), "y": []byte("2"), "z": nil},
+		{"x1": nil, "y": []byte("22"), "z": nil},
+	} {
+		if SumMap256(m) == want {
+			t.Errorf("%q: expected different checksum", m)
+		}
+	}
+	if SumMap256(nil) != Sum256(nil) {
+		t.Errorf("empty map: expected checksum of empty input")
+	}
+}
+
+func TestClose(t *testing.T) {
+	d := NewSalt([]byte("0123456789abcdef")).(*Digest)
+	d.Write(make([]byte, 100))
+	d.Close()
+	if d.h != [8]uint32{} || d.s != [4]uint32{} || d.x != [BlockSize]byte{} || d.nx != 0 {
+		t.Errorf("state is not zeroed")
+	}
+	d.Reset()
+	for name, f := range map[string]func(){
+		"Write":      func() { d.Write([]byte{1}) },
+		"BlockWrite": func() { d.BlockWrite(make([]byte, BlockSize)) },
+		"Sum":        func() { d.Sum(nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic after Close", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+// Checksums of zero bytes for the final 14-round version of BLAKE. The
+// comment on each entry gives its source: "C reference" entries are the
+// test vectors printed by the reference C implementation from the BLAKE
+// submission, "published" entries are the widely published checksums of
+// the empty string, and "blake_ref.py" entries, at lengths chosen around
+// padding boundaries, were computed with testdata/blake_ref.py and have no
+// independent source.
+var zeroVectors = []struct {
+	hashSize int
+	inLen    int
+	out      string
+}{
+	{256, 0, "716f6e863f744b9ac22c97ec7b76ea5f5908bc5b2f67c61510bfc4751384ea7a"},    // published
+	{256, 1, "0ce8d4ef4dd7cd8d62dfded9d4edb0a774ae6a41929a74da23109e8f11139c87"},    // C reference
+	{256, 55, "dc980544f4181cc43505318e317cdfd4334dab81ae035a28818308867ce23060"},   // blake_ref.py
+	{256, 56, "26ae7c289ebb79c9f3af2285023ab1037a9a6db63f0d6b6c6bbd199ab1627508"},   // blake_ref.py
+	{256, 63, "254b522be8c966d8a2c44a2bffce8469f8223ea3371e14e6387d60fc790361f1"},   // blake_ref.py
+	{256, 64, "6d994042954f8dc5633626cd50b2bc66d733a313d67fd9702c5a8149a8028c98"},   // blake_ref.py
+	{256, 65, "081e5d10c8f46e140db4587366c4718462709d000419c1b00ca05a5763cab5cc"},   // blake_ref.py
+	{256, 72, "d419bad32d504fb7d44d460c42c5593fe544fa4c135dec31e21bd9abdcc22d41"},   // C reference
+	{256, 119, "62485b9374ed4f0a788a49ad6e6498173678ad2d4d4d2748539ad42921375ef3"},  // blake_ref.py
+	{256, 120, "a48187b6556da878712df64af27acc800b0e0c492c9f82cd9ecf9354acfac0d7"},  // blake_ref.py
+	{256, 128, "4c8ed99ae2cfdd5bdaba9f19848fcd98b4c60e122096a47ea565c410a1d567ce"},  // blake_ref.py
+	{256, 1000, "e63f5343fc28b480dd8e9586f5cc9b11827e9b317d7a4086326e671b68a5efee"}, // blake_ref.py
+	{224, 0, "7dc5313b1c04512a174bd6503b89607aecbee0903d40a8a569c94eed"},            // published
+	{224, 1, "4504cb0314fb2a4f7a692e696e487912fe3f2468fe312c73a5278ec5"},            // C reference
+	{224, 55, "502a0663e562d1cda878b9fe86e6c475f7399e12379526be742b1c93"},           // blake_ref.py
+	{224, 56, "15b58442b1b486ec9ea2305ab597e751cb754ed29f80c336171b061c"},           // blake_ref.py
+	{224, 63, "788a80bfb7ae13cb65b7110fc369fb2258ff127d8c1673bc039d4aeb"},           // blake_ref.py
+	{224, 64, "268ecee2b76b6ff75b8c73e94165d95e23462296f8a28497ec0cad4d"},           // blake_ref.py
+	{224, 65, "b59929505d87ad6a630558b24f29ab5483041d6658e4de9853de592c"},           // blake_ref.py
+	{224, 72, "f5aa00dd1cb847e3140372af7b5c46b4888d82c8c0a917913cfb5d04"},           // C reference
+	{224, 119, "0ca3b92ba941c60ae59d50a8ec79df70712ad7423eaf7630bf2c44d2"},          // blake_ref.py
+	{224, 120, "286aa9b64d1cf6cd72a34bf02939311802b5139ae842a6d81d52ea10"},          // blake_ref.py
+	{224, 128, "25b41ea47596e8811a3f243d5597897cb10b6df802bbba58e83a3fdf"},          // blake_ref.py
+	{224, 1000, "0728d451fa7ee2ab53586a9b22db0fd1233948c133faa48dd0cf08d9"},         // blake_ref.py
+}
+
+func TestZeroVectors(t *testing.T) {
+	for _, v := range zeroVectors {
+		h, _ := NewSize(v.hashSize)
+		data := make([]byte, v.inLen)
+		for _, chunk := range []int{1, 7, BlockSize, v.inLen + 1} {
+			h.Reset()
+			for p := data; len(p) > 0; {
+				n := chunk
+				if n > len(p) {
+					n = len(p)
+				}
+				h.Write(p[:n])
+				p = p[n:]
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != v.out {
+				t.Errorf("BLAKE-%d(%d zero bytes) in %d-byte writes: expected %s, got %s", v.hashSize, v.inLen, chunk, v.out, got)
+			}
+		}
+	}
+}
+
+func TestCalibrateBest(t *testing.T) {
+	defer x8impl.Store(x8impl.Load())
+	CalibrateBest()
+	t.Logf("selected %s", x8impl.Load().name)
+	for _, v := range zeroVectors {
+		if v.hashSize != 256 {
+			continue
+		}
+		var in [8][]byte
+		for j := range in {
+			in[j] = make([]byte, v.inLen)
+		}
+		for j, sum := range Sum256x8(in) {
+			if got := hex.EncodeToString(sum[:]); got != v.out {
+				t.Errorf("%d zero bytes, lane %d: expected %s, got %s", v.inLen, j, v.out, got)
+			}
+		}
+	}
+}
+
+func TestOrderedHasher(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	want := Sum256(data)
+
+	bounds := []int{0, 1, 64, 100, 333, 334, 700, 1000}
+	o := NewOrderedHasher()
+	for _, i := range []int{3, 6, 1, 0, 5, 4, 2} {
+		if err := o.WriteAt(data[bounds[i]:bounds[i+1]], int64(bounds[i])); err != nil {
+			t.Fatalf("segment %d: %v", i, err)
+		}
+	}
+	got, err := o.Sum(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want[:]) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+
+	o = NewOrderedHasher()
+	if err := o.WriteAt(data[100:200], 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.WriteAt(data[150:250], 150); err == nil {
+		t.Errorf("expected error for overlapping segment")
+	}
+	if err := o.WriteAt(data[:10], -1); err == nil {
+		t.Errorf("expected error for negative offset")
+	}
+	if _, err := o.Sum(nil); err == nil {
+		t.Errorf("expected gap error")
+	}
+	if err := o.WriteAt(data[:100], 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.WriteAt(data[50:60], 50); err == nil {
+		t.Errorf("expected error for rewriting hashed data")
+	}
+	got, _ = o.Sum(nil)
+	if want := Sum256(data[:200]); !bytes.Equal(got, want[:]) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+}
+
+func TestChecksumValueScan(t *testing.T) {
+	for _, d := range []*Digest{NewDigest(), NewDigest224()} {
+		d.Write([]byte("value"))
+		c := d.Checksum()
+		v, err := c.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, ok := v.([]byte)
+		if !ok || !bytes.Equal(b, d.Sum(nil)) {
+			t.Fatalf("%d: Value returned %#v", d.SizeBits(), v)
+		}
+		var c2 Checksum
+		if err := c2.Scan(b); err != nil {
+			t.Fatal(err)
+		}
+		b[0] ^= 1 // Scan must copy
+		c = d.Checksum()
+		if !bytes.Equal(c2, c) {
+			t.Errorf("%d: expected %x, got %x", d.SizeBits(), c, c2)
+		}
+		if err := c2.Scan(string(c)); err != nil || !bytes.Equal(c2, c) {
+			t.Errorf("%d: Scan(string): %x, %v", d.SizeBits(), c2, err)
+		}
+	}
+
+	var c Checksum
+	if v, err := c.Value(); v != nil || err != nil {
+		t.Errorf("nil Checksum: Value returned %v, %v", v, err)
+	}
+	c = Checksum{1}
+	if err := c.Scan(nil); err != nil || c != nil {
+		t.Errorf("Scan(nil): %x, %v", c, err)
+	}
+	if err := c.Scan([]byte{1, 2, 3}); err == nil {
+		t.Errorf("expected error for short value")
+	}
+	if err := c.Scan(int64(1)); err == nil {
+		t.Errorf("expected error for int64 value")
+	}
+}
+
+func TestConsume(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	ch := make(chan []byte)
+	go func() {
+		for p := data; len(p) > 0; {
+			n := min(len(p), 37)
+			ch <- p[:n]
+			ch <- nil
+			ch <- []byte{}
+			p = p[n:]
+		}
+		close(ch)
+	}()
+	d := NewDigest()
+	if err := d.Consume(ch); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.Sum(nil), Sum256(data); !bytes.Equal(got, want[:]) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+
+	ch = make(chan []byte, 2)
+	ch <- []byte{1}
+	ch <- []byte{2}
+	close(ch)
+	d = NewDigest()
+	d.writeBits([]byte{0x80}, 1)
+	if err := d.Consume(ch); err == nil {
+		t.Errorf("expected error after partial byte")
+	}
+	if len(ch) != 0 {
+		t.Errorf("channel is not drained")
+	}
+}
+
+func TestSumGzip256(t *testing.T) {
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i * i)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data[:50000])
+	zw.Close()
+	zw = gzip.NewWriter(&buf)
+	zw.Write(data[50000:])
+	zw.Close()
+	compressed := buf.Bytes()
+
+	sum, err := SumGzip256(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Sum256(data); sum != want {
+		t.Errorf("expected %x, got %x", want, sum)
+	}
+
+	if _, err := SumGzip256(bytes.NewReader(data)); err == nil {
+		t.Errorf("expected error for non-gzip input")
+	}
+	if _, err := SumGzip256(bytes.NewReader(compressed[:len(compressed)-5])); err == nil {
+		t.Errorf("expected error for truncated input")
+	}
+	corrupt := append([]byte(nil), compressed...)
+	corrupt[len(corrupt)-6] ^= 1 // CRC-32 of the last member
+	if _, err := SumGzip256(bytes.NewReader(corrupt)); err == nil {
+		t.Errorf("expected error for corrupted input")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	d := NewDigest()
+	d.Write([]byte("fingerprint"))
+	sum := d.Sum(nil)
+	for _, n := range []int{1, 4, 16, Size} {
+		var parts []string
+		for _, c := range sum[:n] {
+			parts = append(parts, fmt.Sprintf("%02x", c))
+		}
+		if got, want := d.Fingerprint(n), strings.Join(parts, ":"); got != want {
+			t.Errorf("%d: expected %s, got %s", n, want, got)
+		}
+	}
+	for _, n := range []int{0, -1, Size + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%d: expected panic", n)
+				}
+			}()
+			d.Fingerprint(n)
+		}()
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("BLAKE-224: expected panic for %d bytes", Size)
+			}
+		}()
+		NewDigest224().Fingerprint(Size)
+	}()
+}
+
+func TestRehashWithSalt(t *testing.T) {
+	for _, d := range []*Digest{NewDigest(), NewDigest224()} {
+		d.Write([]byte("leftover"))
+		for i, v := range vectors256salt {
+			got, err := d.RehashWithSalt([]byte(v.salt), []byte(v.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, _ := NewSize(d.SizeBits())
+			ref.(*Digest).SetSalt([]byte(v.salt))
+			ref.Write([]byte(v.in))
+			if want := ref.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%d: %d: expected %x, got %x", d.SizeBits(), i, want, got)
+			}
+		}
+		if _, err := d.RehashWithSalt([]byte("short"), nil); err != ErrSaltLength {
+			t.Errorf("expected ErrSaltLength, got %v", err)
+		}
+	}
+}
+
+// merkleProof builds a Merkle tree of leaves and returns its root and the
+// inclusion proof of leaves[index]. An odd node at the end of a level is
+// promoted to the next level unchanged.
+func merkleProof(leaves [][]byte, index int) (root []byte, proof [][]byte, directions []bool) {
+	var level [][]byte
+	for _, l := range leaves {
+		sum := Sum256(append([]byte{0}, l...))
+		level = append(level, sum[:])
+	}
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			if index == i || index == i+1 {
+				right := index == i
+				if right {
+					proof = append(proof, level[i+1])
+				} else {
+					proof = append(proof, level[i])
+				}
+				directions = append(directions, right)
+			}
+			sum := Sum256(append(append([]byte{1}, level[i]...), level[i+1]...))
+			next = append(next, sum[:])
+		}
+		index /= 2
+		level = next
+	}
+	return level[0], proof, directions
+}
+
+func TestVerifyMerkleProof(t *testing.T) {
+	leaves := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	for i, leaf := range leaves {
+		root, proof, dirs := merkleProof(leaves, i)
+		if !VerifyMerkleProof(leaf, proof, dirs, root) {
+			t.Errorf("%d: valid proof rejected", i)
+		}
+		if VerifyMerkleProof([]byte("x"), proof, dirs, root) {
+			t.Errorf("%d: proof accepted for wrong leaf", i)
+		}
+		if len(proof) > 0 {
+			bad := append([]bool(nil), dirs...)
+			bad[0] = !bad[0]
+			if VerifyMerkleProof(leaf, proof, bad, root) {
+				t.Errorf("%d: proof accepted with wrong directions", i)
+			}
+			if VerifyMerkleProof(leaf, proof, dirs[1:], root) {
+				t.Errorf("%d: proof accepted with mismatched directions", i)
+			}
+		}
+	}
+	// A leaf must not verify as an interior node.
+	root, _, _ := merkleProof(leaves[:2], 0)
+	inner := append(mustSum(0, "a"), mustSum(0, "b")...)
+	if VerifyMerkleProof(inner, nil, nil, root) {
+		t.Errorf("interior node accepted as leaf")
+	}
+	if !VerifyMerkleProof([]byte("a"), nil, nil, mustSum(0, "a")) {
+		t.Errorf("single-leaf tree rejected")
+	}
+}
+
+func mustSum(prefix byte, s string) []byte {
+	sum := Sum256(append([]byte{prefix}, s...))
+	return sum[:]
+}
+
+func TestSaltWords(t *testing.T) {
+	salt := []byte{
+		0x00, 0x01, 0x02, 0x03, 0x10, 0x11, 0x12, 0x13,
+		0xa0, 0xb1, 0xc2, 0xd3, 0xff, 0xfe, 0xfd, 0xfc,
+	}
+	want := [4]uint32{0x00010203, 0x10111213, 0xa0b1c2d3, 0xfffefdfc}
+	if got := NewSalt(salt).(*Digest).SaltWords(); got != want {
+		t.Errorf("expected %08x, got %08x", want, got)
+	}
+	if got := NewDigest().SaltWords(); got != [4]uint32{} {
+		t.Errorf("unsalted: expected zero words, got %08x", got)
+	}
+}
+
+func TestResumableHasher(t *testing.T) {
+	data := make([]byte, 5000)
+	for i := range data {
+		data[i] = byte(i * 11)
+	}
+	var state []byte
+	for _, end := range []int{0, 1, 63, 64, 1000, 1001, 4096, 5000} {
+		r := NewResumableHasher()
+		if state != nil {
+			if err := r.UnmarshalBinary(state); err != nil {
+				t.Fatal(err)
+			}
+		}
+		off := r.Offset()
+		r.Append(data[off:end])
+		if r.Offset() != int64(end) {
+			t.Errorf("expected offset %d, got %d", end, r.Offset())
+		}
+		if got, want := r.PartialSum(), Sum256(data[:end]); got != want {
+			t.Errorf("%d bytes: expected %x, got %x", end, want, got)
+		}
+		var err error
+		if state, err = r.MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h224, _ := NewDigest224().MarshalBinary()
+	if err := new(ResumableHasher).UnmarshalBinary(h224); err == nil {
+		t.Errorf("expected error for BLAKE-224 state")
+	}
+	if err := new(ResumableHasher).UnmarshalBinary(state[:10]); err == nil {
+		t.Errorf("expected error for truncated state")
+	}
+}
+
+func TestSumSection256(t *testing.T) {
+	data := make([]byte, 3*streamBufSize+100)
+	for i := range data {
+		data[i] = byte(i * 5)
+	}
+	r := bytes.NewReader(data)
+	for _, s := range []struct{ off, length int }{
+		{0, 0}, {0, len(data)}, {1, 63}, {100, streamBufSize}, {7, 2*streamBufSize + 1}, {len(data) - 10, 10},
+	} {
+		sum, err := SumSection256(r, int64(s.off), int64(s.length))
+		if err != nil {
+			t.Fatalf("%v: %v", s, err)
+		}
+		if want := Sum256(data[s.off : s.off+s.length]); sum != want {
+			t.Errorf("%v: expected %x, got %x", s, want, sum)
+		}
+	}
+	if _, err := SumSection256(r, int64(len(data)-10), 11); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := SumSection256(r, int64(len(data)+1), 1); err != io.ErrUnexpectedEOF {
+		t.Errorf("past end: expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := SumSection256(r, -1, 1); err == nil {
+		t.Errorf("expected error for negative offset")
+	}
+}
+
+func TestSumValue256(t *testing.T) {
+	type inner struct {
+		N int
+		f float64
+	}
+	type outer struct {
+		Name  string
+		Data  []byte
+		Tags  map[string]int
+		Inner *inner
+		Any   any
+		Arr   [2]uint16
+	}
+	mk := func() outer {
+		tags := make(map[string]int)
+		for _, k := range []string{"a", "b", "c", "d"} {
+			tags[k] = len(k)
+		}
+		return outer{"x", []byte("data"), tags, &inner{1, 2.5}, []int{1, 2}, [2]uint16{3, 4}}
+	}
+	a, b := mk(), mk()
+	b.Tags = map[string]int{"d": 1, "c": 1, "b": 1, "a": 1}
+	sa, err := SumValue256(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := SumValue256(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sa != sb {
+		t.Errorf("equal values have different checksums")
+	}
+
+	for i, m := range []func(*outer){
+		func(o *outer) { o.Name = "y" },
+		func(o *outer) { o.Data = nil },
+		func(o *outer) { o.Tags["e"] = 0 },
+		func(o *outer) { o.Inner = nil },
+		func(o *outer) { o.Inner.f = 3 },
+		func(o *outer) { o.Any = nil },
+		func(o *outer) { o.Arr[1] = 5 },
+	} {
+		c := mk()
+		m(&c)
+		if sc, _ := SumValue256(c); sc == sa {
+			t.Errorf("%d: different values have the same checksum", i)
+		}
+	}
+
+	// Strings and byte slices are encoded as by StructHasher, after
+	// the marker of non-nil interface.
+	sh := NewStructHasher()
+	sh.Write([]byte{1})
+	sh.AddString("ab")
+	for _, v := range []any{"ab", []byte("ab")} {
+		got, _ := SumValue256(v)
+		if !bytes.Equal(got[:], sh.Sum(nil)) {
+			t.Errorf("%T: unexpected encoding", v)
+		}
+	}
+
+	type cyclic struct{ Next *cyclic }
+	cyc := &cyclic{}
+	cyc.Next = cyc
+	for _, v := range []any{func() {}, make(chan int), struct{ F func() }{}, cyc} {
+		if _, err := SumValue256(v); err == nil {
+			t.Errorf("%T: expected error", v)
+		}
+	}
+	if _, err := SumValue256(nil); err != nil {
+		t.Errorf("nil: %v", err)
+	}
+}
+
+func TestWriteUint(t *testing.T) {
+	d := NewDigest()
+	d.WriteUint16(0x0102)
+	d.WriteUint32(0x03040506)
+	d.WriteUint64(0x0708090a0b0c0d0e)
+	ref := NewDigest()
+	ref.Write([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14})
+	if got, want := d.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+	if n := testing.AllocsPerRun(10, func() { d.WriteUint64(1) }); n != 0 {
+		t.Errorf("WriteUint64: %v allocations", n)
+	}
+}
+
+func TestMACVerifier(t *testing.T) {
+	key := []byte("secret key")
+	msg := []byte("message to authenticate")
+	mac := hmac.New(New, key)
+	mac.Write(msg)
+	tag := mac.Sum(nil)
+
+	var buf bytes.Buffer
+	v := NewMACVerifier(key, &buf)
+	v.Write(msg[:5])
+	v.Write(msg[5:])
+	if !v.VerifyTag(tag) {
+		t.Errorf("valid tag rejected")
+	}
+	if !bytes.Equal(buf.Bytes(), msg) {
+		t.Errorf("data not written through: %q", buf.Bytes())
+	}
+	bad := append([]byte(nil), tag...)
+	bad[len(bad)-1] ^= 1
+	if v.VerifyTag(bad) || v.VerifyTag(tag[:Size-1]) {
+		t.Errorf("tampered tag accepted")
+	}
+
+	v = NewMACVerifier(key, nil)
+	v.Write([]byte("Message to authenticate"))
+	if v.VerifyTag(tag) {
+		t.Errorf("tag accepted for tampered message")
+	}
+
+	v = NewMACVerifier(key, errWriter{})
+	if _, err := v.Write(msg); err == nil {
+		t.Errorf("expected write error")
+	}
+	if !v.VerifyTag(NewMACKeyed(key).Sum(nil)) {
+		t.Errorf("data rejected by writer was authenticated")
+	}
+}
+
+func TestWrite13(t *testing.T) {
+	// 13 is coprime with BlockSize, so the buffer is left with every
+	// possible number of bytes and writes cross block boundaries at every
+	// offset.
+	data := make([]byte, 13*BlockSize*3)
+	for i := range data {
+		data[i] = byte(i*31 + 7)
+	}
+	salt := []byte("0123456789abcdef")
+	for _, newHash := range []func() hash.Hash{New, New224, func() hash.Hash { return NewSalt(salt) }} {
+		h, ref := newHash(), newHash()
+		for n := 0; n < len(data); n += 13 {
+			h.Write(data[n : n+13])
+			ref.Reset()
+			ref.Write(data[:n+13])
+			if got, want := h.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("%d bytes: expected %x, got %x", n+13, want, got)
+			}
+		}
+	}
+}
+
+func TestPRNG(t *testing.T) {
+	a := make([]byte, 1000)
+	io.ReadFull(NewPRNG([]byte("seed")), a)
+
+	// Reads of different sizes produce the same stream.
+	b := make([]byte, len(a))
+	r := NewPRNG([]byte("seed"))
+	for i := 0; i < len(b); {
+		n := min(len(b)-i, i%37+1)
+		r.Read(b[i : i+n])
+		i += n
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("streams for the same seed differ")
+	}
+
+	for i := 0; i < 3; i++ {
+		want := Sum256(append([]byte("seed"), 0, 0, 0, 0, 0, 0, 0, byte(i)))
+		if !bytes.Equal(a[i*Size:(i+1)*Size], want[:]) {
+			t.Errorf("block %d: expected %x, got %x", i, want, a[i*Size:(i+1)*Size])
+		}
+	}
+
+	io.ReadFull(NewPRNG([]byte("seed2")), b)
+	if bytes.Equal(a[:Size], b[:Size]) {
+		t.Errorf("streams for different seeds are the same")
+	}
+}
+
+func TestResetIV(t *testing.T) {
+	links := [][]byte{[]byte("first"), make([]byte, 100), []byte("third")}
+
+	d := NewDigest()
+	d.Write([]byte("leftover"))
+	var iv [8]uint32
+	for i, link := range links {
+		if i == 0 {
+			d.Reset()
+		} else {
+			d.ResetIV(iv)
+		}
+		d.Write(link)
+		sum := d.Sum(nil)
+
+		// Equivalent computation with the chain value set directly.
+		ref := &Digest{hashSize: 256, h: iv256}
+		if i > 0 {
+			ref.h = iv
+		}
+		ref.Write(link)
+		if want := ref.Sum(nil); !bytes.Equal(sum, want) {
+			t.Errorf("link %d: expected %x, got %x", i, want, sum)
+		}
+		for j := range iv {
+			iv[j] = uint32(sum[4*j])<<24 | uint32(sum[4*j+1])<<16 | uint32(sum[4*j+2])<<8 | uint32(sum[4*j+3])
+		}
+	}
+
+	d.ResetIV(iv256)
+	d.Write(links[0])
+	if got, want := d.Sum(nil), Sum256(links[0]); !bytes.Equal(got, want[:]) {
+		t.Errorf("standard IV: expected %x, got %x", want, got)
+	}
+}
+
+func TestHMACTag(t *testing.T) {
+	key, msg := []byte("key"), []byte("message")
+	mac := hmac.New(New, key)
+	mac.Write(msg)
+	full := mac.Sum(nil)
+	for _, n := range []int{1, 10, 16, Size} {
+		tag, err := HMACTag(key, msg, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(tag, full[:n]) {
+			t.Errorf("%d: expected %x, got %x", n, full[:n], tag)
+		}
+		check, _ := HMACTag(key, msg, n)
+		if !hmac.Equal(tag, check) {
+			t.Errorf("%d: verification failed", n)
+		}
+		forged, _ := HMACTag(key, []byte("massage"), n)
+		if n > 4 && hmac.Equal(tag, forged) {
+			t.Errorf("%d: tag verified for another message", n)
+		}
+	}
+	for _, n := range []int{0, -1, Size + 1} {
+		if _, err := HMACTag(key, msg, n); err == nil {
+			t.Errorf("%d: expected error", n)
+		}
+	}
+}
+
+func TestChunkFingerprint(t *testing.T) {
+	data := []byte("chunk")
+	want := Sum256([]byte("\x01\x02\x03\x04chunk"))
+	if got := ChunkFingerprint(0x01020304, data); got != want {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+	seen := make(map[[Size]byte]uint32)
+	for _, i := range []uint32{0, 1, 2, 1 << 8, 1 << 31, 0xffffffff} {
+		fp := ChunkFingerprint(i, data)
+		if j, ok := seen[fp]; ok {
+			t.Errorf("indexes %d and %d have the same fingerprint", i, j)
+		}
+		seen[fp] = i
+	}
+}
+
+func TestHashTypes(t *testing.T) {
+	h := Sum256([]byte("a"))
+	want := hex.EncodeToString(h[:])
+	if h.String() != want || h.Hex() != want || fmt.Sprint(h) != want {
+		t.Errorf("expected %s, got %s, %s, %s", want, h.String(), h.Hex(), fmt.Sprint(h))
+	}
+	// %x and %X format the bytes, as for a plain array.
+	if got := fmt.Sprintf("%x", h); got != want {
+		t.Errorf("%%x: expected %s, got %s", want, got)
+	}
+	if got, want := fmt.Sprintf("%X", h), fmt.Sprintf("%X", h[:]); got != want {
+		t.Errorf("%%X: expected %s, got %s", want, got)
+	}
+	if h != Sum256([]byte("a")) || h == Sum256([]byte("b")) {
+		t.Errorf("unexpected result of comparison")
+	}
+	b := h.Bytes()
+	b[0] ^= 1
+	if h[0] == b[0] {
+		t.Errorf("Bytes doesn't return a copy")
+	}
+
+	h224 := Sum224([]byte("a"))
+	if want := hex.EncodeToString(h224[:]); h224.String() != want || h224.Hex() != want ||
+		fmt.Sprintf("%x", h224) != want {
+		t.Errorf("expected %s, got %s, %s, %x", want, h224.String(), h224.Hex(), h224)
+	}
+	if !bytes.Equal(h224.Bytes(), h224[:]) {
+		t.Errorf("Bytes: expected %x, got %x", h224[:], h224.Bytes())
+	}
+
+	seen := map[Hash256]string{Sum256([]byte("a")): "a", Sum256([]byte("b")): "b"}
+	if seen[h] != "a" || len(seen) != 2 {
+		t.Errorf("unexpected map contents: %v", seen)
+	}
+}
+
+func TestSumIdempotent(t *testing.T) {
+	data := make([]byte, 200)
+	salt := []byte("0123456789abcdef")
+	for _, newHash := range []func() hash.Hash{New, New224, func() hash.Hash { return NewSalt(salt) }} {
+		for _, n := range []int{0, 1, 55, 56, 64, 65, 200} {
+			h := newHash()
+			h.Write(data[:n/2])
+			first := h.Sum(nil)
+			if second := h.Sum(nil); !bytes.Equal(first, second) {
+				t.Errorf("%d bytes: repeated Sum returned %x, then %x", n, first, second)
+			}
+			// The hasher must still be usable after Sum.
+			h.Write(data[n/2 : n])
+			ref := newHash()
+			ref.Write(data[:n])
+			if got, want := h.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%d bytes: Write after Sum: expected %x, got %x", n, want, got)
+			}
+		}
+	}
+}
+
+// address is an example of generic code parameterized by Algorithm.
+func address[A Algorithm](data []byte) []byte {
+	var a A
+	h := a.New()
+	h.Write(data)
+	if sum := h.Sum(nil); len(sum) == a.Size() {
+		return sum
+	}
+	return nil
+}
+
+func TestAlgorithm(t *testing.T) {
+	data := []byte("content")
+	want256 := Sum256(data)
+	if got := address[BLAKE256](data); !bytes.Equal(got, want256[:]) {
+		t.Errorf("BLAKE256: expected %x, got %x", want256, got)
+	}
+	want224 := Sum224(data)
+	if got := address[BLAKE224](data); !bytes.Equal(got, want224[:]) {
+		t.Errorf("BLAKE224: expected %x, got %x", want224, got)
+	}
+}
+
+func TestTentativeSum(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	d := NewDigest()
+	d224 := NewDigest224()
+	for n := 0; n < len(data); n += 25 {
+		if got, want := d.TentativeSum(), Sum256(data[:n]); got != want {
+			t.Errorf("%d bytes: expected %x, got %x", n, want, got)
+		}
+		got224 := d224.TentativeSum()
+		if want := Sum224(data[:n]); !bytes.Equal(got224[:Size224], want[:]) || !bytes.Equal(got224[Size224:], make([]byte, Size-Size224)) {
+			t.Errorf("BLAKE-224, %d bytes: expected %x, got %x", n, want, got224)
+		}
+		d.Write(data[n : n+25])
+		d224.Write(data[n : n+25])
+	}
+}
+
+func TestX8Implementations(t *testing.T) {
+	// Only the eight-way hashing has several implementations; block has
+	// a single portable one.
+	defer x8impl.Store(x8impl.Load())
+	for _, impl := range x8Implementations {
+		x8impl.Store(impl)
+		for i, v := range vectors256 {
+			var in [8][]byte
+			for j := range in {
+				in[j] = []byte(v.in)
+			}
+			for j, sum := range Sum256x8(in) {
+				if got := hex.EncodeToString(sum[:]); got != v.out {
+					t.Errorf("%s: vector %d, lane %d: expected %s, got %s", impl.name, i, j, v.out, got)
+				}
+			}
+		}
+		for _, v := range zeroVectors {
+			if v.hashSize != 256 {
+				continue
+			}
+			var in [8][]byte
+			for j := range in {
+				in[j] = make([]byte, v.inLen)
+			}
+			for j, sum := range Sum256x8(in) {
+				if got := hex.EncodeToString(sum[:]); got != v.out {
+					t.Errorf("%s: %d zero bytes, lane %d: expected %s, got %s", impl.name, v.inLen, j, v.out, got)
+				}
+			}
+		}
+		buf := make([]byte, 8*200)
+		rand.New(rand.NewSource(1)).Read(buf)
+		for n := 0; n <= 200; n++ {
+			var in [8][]byte
+			for j := range in {
+				in[j] = buf[j*200 : j*200+n]
+			}
+			for j, sum := range Sum256x8(in) {
+				if want := Sum256(in[j]); sum != want {
+					t.Errorf("%s: %d bytes, lane %d: expected %x, got %x", impl.name, n, j, want, sum)
+				}
+			}
+		}
+	}
+}
+
+func TestSumCount256(t *testing.T) {
+	data := make([]byte, 3*streamBufSize+7)
+	for _, n := range []int{0, 1, 64, len(data)} {
+		sum, count, err := SumCount256(iotest.HalfReader(bytes.NewReader(data[:n])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != int64(n) {
+			t.Errorf("expected count %d, got %d", n, count)
+		}
+		if want := Sum256(data[:n]); sum != want {
+			t.Errorf("%d bytes: expected %x, got %x", n, want, sum)
+		}
+	}
+	r := io.MultiReader(bytes.NewReader(data[:100]), iotest.ErrReader(iotest.ErrTimeout))
+	if _, count, err := SumCount256(r); err != iotest.ErrTimeout || count != 100 {
+		t.Errorf("expected 100, ErrTimeout, got %d, %v", count, err)
+	}
+}
+
+func TestNewMultiWriter(t *testing.T) {
+	data := make([]byte, 100000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	b, s := New(), sha256.New()
+	n, err := io.Copy(NewMultiWriter(b, s), iotest.OneByteReader(bytes.NewReader(data[:1000])))
+	if err != nil || n != 1000 {
+		t.Fatalf("io.Copy returned %d, %v", n, err)
+	}
+	io.Copy(NewMultiWriter(b, s), bytes.NewReader(data[1000:]))
+	if got, want := b.Sum(nil), Sum256(data); !bytes.Equal(got, want[:]) {
+		t.Errorf("BLAKE-256: expected %x, got %x", want, got)
+	}
+	if got, want := s.Sum(nil), sha256.Sum256(data); !bytes.Equal(got, want[:]) {
+		t.Errorf("SHA-256: expected %x, got %x", want, got)
+	}
+
+	limited, err := NewLimited(500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err = io.Copy(NewMultiWriter(limited, sha256.New()), bytes.NewReader(data[:1000]))
+	if !errors.Is(err, ErrLimitExceeded) || n != 500 {
+		t.Errorf("expected 500, %v; got %d, %v", ErrLimitExceeded, n, err)
+	}
+}
+
+func TestSumPrefix(t *testing.T) {
+	for _, h := range []hash.Hash{New(), New224()} {
+		h.Write([]byte("prefix"))
+		sum := h.Sum(nil)
+
+		buf := make([]byte, 100)
+		for i := range buf {
+			buf[i] = 0xaa
+		}
+		prefix := buf[:3]
+		copy(prefix, "abc")
+		out := h.Sum(prefix)
+		if len(out) != 3+h.Size() {
+			t.Fatalf("expected length %d, got %d", 3+h.Size(), len(out))
+		}
+		if &out[0] != &buf[0] {
+			t.Errorf("Sum didn't use the spare capacity of prefix")
+		}
+		if string(prefix) != "abc" || string(out[:3]) != "abc" {
+			t.Errorf("prefix changed: %q, %q", prefix, out[:3])
+		}
+		if !bytes.Equal(out[3:], sum) {
+			t.Errorf("expected %x, got %x", sum, out[3:])
+		}
+		for i := 3 + h.Size(); i < len(buf); i++ {
+			if buf[i] != 0xaa {
+				t.Fatalf("byte %d after checksum was overwritten", i)
+			}
+		}
+	}
+}
+
+func TestSumVectored(t *testing.T) {
+	for _, d := range []*Digest{NewDigest(), NewDigest224()} {
+		d.Write([]byte("vectored"))
+		sum := d.Sum(nil)
+		for _, sizes := range [][]int{{16, 16}, {10, 22}, {32}, {0, 5, 0, 40}, {28}, {1, 1, 30, 8}} {
+			total := 0
+			var bufs [][]byte
+			for _, n := range sizes {
+				bufs = append(bufs, make([]byte, n))
+				total += n
+			}
+			err := d.SumVectored(bufs)
+			if total < d.Size() {
+				if err == nil {
+					t.Errorf("%d, %v: expected error", d.SizeBits(), sizes)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%d, %v: %v", d.SizeBits(), sizes, err)
+			}
+			if got := bytes.Join(bufs, nil); !bytes.Equal(got[:d.Size()], sum) || !bytes.Equal(got[d.Size():], make([]byte, total-d.Size())) {
+				t.Errorf("%d, %v: expected %x, got %x", d.SizeBits(), sizes, sum, got)
+			}
+		}
+		bufs := [][]byte{make([]byte, 10), make([]byte, 10)}
+		if err := d.SumVectored(bufs); err == nil {
+			t.Errorf("%d: expected error for undersized buffers", d.SizeBits())
+		}
+		if !bytes.Equal(bytes.Join(bufs, nil), make([]byte, 20)) {
+			t.Errorf("%d: buffers changed on error", d.SizeBits())
+		}
+	}
+}
+
+func TestNewSaltStrict(t *testing.T) {
+	salt1, salt2 := []byte("0123456789abcdef"), []byte("fedcba9876543210")
+	msg := []byte("message")
+
+	h := NewSaltStrict(salt1)
+	h.Write(msg)
+	ref := NewSalt(salt1)
+	ref.Write(msg)
+	want := ref.Sum(nil)
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+
+	// Standard Reset keeps salt.
+	ref.Reset()
+	ref.Write(msg)
+	if got := ref.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("standard Reset: expected %x, got %x", want, got)
+	}
+
+	h.Reset()
+	for name, f := range map[string]func(){
+		"Write": func() { h.Write(msg) },
+		"Sum":   func() { h.Sum(nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic without salt after Reset", name)
+				}
+			}()
+			f()
+		}()
+	}
+
+	s := h.(interface{ SetSalt([]byte) error })
+	if err := s.SetSalt(salt2[:15]); err != ErrSaltLength {
+		t.Errorf("expected ErrSaltLength, got %v", err)
+	}
+	if err := s.SetSalt(salt2); err != nil {
+		t.Fatal(err)
+	}
+	h.Write(msg)
+	ref = NewSalt(salt2)
+	ref.Write(msg)
+	if got, want := h.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("after SetSalt: expected %x, got %x", want, got)
+	}
+}
+
+func TestSumLines256(t *testing.T) {
+	long := strings.Repeat("log line\n", 2*streamBufSize/9)
+	for _, tt := range []struct {
+		in    string
+		lines int
+	}{
+		{"", 0},
+		{"\n", 1},
+		{"no newline", 0},
+		{"a\nb\nc\n", 3},
+		{"a\nb\nc", 2},
+		{"\n\n\n", 3},
+		{long, strings.Count(long, "\n")},
+	} {
+		sum, lines, err := SumLines256(iotest.HalfReader(strings.NewReader(tt.in)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines != tt.lines {
+			t.Errorf("%.20q: expected %d lines, got %d", tt.in, tt.lines, lines)
+		}
+		if want := Sum256([]byte(tt.in)); sum != want {
+			t.Errorf("%.20q: expected %x, got %x", tt.in, want, sum)
+		}
+	}
+	r := io.MultiReader(strings.NewReader("a\nb\n"), iotest.ErrReader(iotest.ErrTimeout))
+	if _, lines, err := SumLines256(r); err != iotest.ErrTimeout || lines != 2 {
+		t.Errorf("expected 2, ErrTimeout, got %d, %v", lines, err)
+	}
+}
+
+func TestFinalize(t *testing.T) {
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 55, 56, 64, 200} {
+		for _, d := range []*Digest{NewDigest(), NewDigest224()} {
+			d.Write(data[:n])
+			std, alt := byte(0x01), byte(0x00)
+			if d.SizeBits() == 224 {
+				std, alt = 0x00, 0x01
+			}
+			want := d.Sum(nil)
+			if got := d.Finalize(std); !bytes.Equal(got, want) {
+				t.Errorf("%d, %d bytes: expected %x, got %x", d.SizeBits(), n, want, got)
+			}
+			if got := d.Finalize(alt); bytes.Equal(got, want) {
+				t.Errorf("%d, %d bytes: marker %#x produced the standard checksum", d.SizeBits(), n, alt)
+			}
+			// At 55 bytes the padding bit and the marker share a byte, so
+			// high-bit markers would collide with it.
+			for _, m := range []byte{0x02, 0x40, 0x80, 0x81, 0xff} {
+				func() {
+					defer func() {
+						if recover() == nil {
+							t.Errorf("%d, %d bytes: marker %#x was accepted", d.SizeBits(), n, m)
+						}
+					}()
+					d.Finalize(m)
+				}()
+			}
+			if got := d.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%d, %d bytes: Finalize changed the state", d.SizeBits(), n)
+			}
+		}
+	}
+	// The last message bits written by writeBits share the marker byte too.
+	d := NewDigest()
+	d.Write(data[:55])
+	d.writeBits([]byte{0xfe}, 7)
+	if bytes.Equal(d.Finalize(0x00), d.Sum(nil)) {
+		t.Errorf("55 bytes and 7 bits: marker 0x00 produced the standard checksum")
+	}
+}
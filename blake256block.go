@@ -11,6 +11,11 @@
 
 package blake256
 
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
 const (
 	cst0  = 0x243F6A88
 	cst1  = 0x85A308D3
@@ -30,9 +35,29 @@ const (
 	cst15 = 0xB5470917
 )
 
-func block(d *digest, p []uint8) {
+func block(d *Digest, p []uint8) {
+	if d.tweak != nil && len(p) > BlockSize {
+		// Salt changes with each block.
+		for len(p) >= BlockSize {
+			block(d, p[:BlockSize])
+			p = p[BlockSize:]
+		}
+		return
+	}
 	h0, h1, h2, h3, h4, h5, h6, h7 := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
 	s0, s1, s2, s3 := d.s[0], d.s[1], d.s[2], d.s[3]
+	if d.tweak != nil {
+		tw := d.tweak(uint64(d.blocks))
+		s0 ^= tw[0]
+		s1 ^= tw[1]
+		s2 ^= tw[2]
+		s3 ^= tw[3]
+	}
+	d.blocks += len(p) / BlockSize
+
+	// Keep the counter in 32-bit halves to avoid 64-bit arithmetic
+	// in the loop, which is costly on 32-bit and wasm targets.
+	t0, t1 := uint32(d.t), uint32(d.t>>32)
 
 	for len(p) >= BlockSize {
 		v0, v1, v2, v3, v4, v5, v6, v7 := h0, h1, h2, h3, h4, h5, h6, h7
@@ -44,1627 +69,1687 @@ func block(d *digest, p []uint8) {
 		v13 := uint32(cst5)
 		v14 := uint32(cst6)
 		v15 := uint32(cst7)
-		d.t += 512
+		var c uint32
+		t0, c = bits.Add32(t0, 512, 0)
+		t1 += c
 		if !d.nullt {
-			v12 ^= uint32(d.t)
-			v13 ^= uint32(d.t)
-			v14 ^= uint32(d.t >> 32)
-			v15 ^= uint32(d.t >> 32)
+			v12 ^= t0
+			v13 ^= t0
+			v14 ^= t1
+			v15 ^= t1
 		}
+		// m is indexed only by constants, so it stays on the stack
+		// (verify with go build -gcflags=-m).
 		var m [16]uint32
 
-		m[0] = uint32(p[0])<<24 | uint32(p[1])<<16 | uint32(p[2])<<8 | uint32(p[3])
-		m[1] = uint32(p[4])<<24 | uint32(p[5])<<16 | uint32(p[6])<<8 | uint32(p[7])
-		m[2] = uint32(p[8])<<24 | uint32(p[9])<<16 | uint32(p[10])<<8 | uint32(p[11])
-		m[3] = uint32(p[12])<<24 | uint32(p[13])<<16 | uint32(p[14])<<8 | uint32(p[15])
-		m[4] = uint32(p[16])<<24 | uint32(p[17])<<16 | uint32(p[18])<<8 | uint32(p[19])
-		m[5] = uint32(p[20])<<24 | uint32(p[21])<<16 | uint32(p[22])<<8 | uint32(p[23])
-		m[6] = uint32(p[24])<<24 | uint32(p[25])<<16 | uint32(p[26])<<8 | uint32(p[27])
-		m[7] = uint32(p[28])<<24 | uint32(p[29])<<16 | uint32(p[30])<<8 | uint32(p[31])
-		m[8] = uint32(p[32])<<24 | uint32(p[33])<<16 | uint32(p[34])<<8 | uint32(p[35])
-		m[9] = uint32(p[36])<<24 | uint32(p[37])<<16 | uint32(p[38])<<8 | uint32(p[39])
-		m[10] = uint32(p[40])<<24 | uint32(p[41])<<16 | uint32(p[42])<<8 | uint32(p[43])
-		m[11] = uint32(p[44])<<24 | uint32(p[45])<<16 | uint32(p[46])<<8 | uint32(p[47])
-		m[12] = uint32(p[48])<<24 | uint32(p[49])<<16 | uint32(p[50])<<8 | uint32(p[51])
-		m[13] = uint32(p[52])<<24 | uint32(p[53])<<16 | uint32(p[54])<<8 | uint32(p[55])
-		m[14] = uint32(p[56])<<24 | uint32(p[57])<<16 | uint32(p[58])<<8 | uint32(p[59])
-		m[15] = uint32(p[60])<<24 | uint32(p[61])<<16 | uint32(p[62])<<8 | uint32(p[63])
+		m[0] = binary.BigEndian.Uint32(p[0:])
+		m[1] = binary.BigEndian.Uint32(p[4:])
+		m[2] = binary.BigEndian.Uint32(p[8:])
+		m[3] = binary.BigEndian.Uint32(p[12:])
+		m[4] = binary.BigEndian.Uint32(p[16:])
+		m[5] = binary.BigEndian.Uint32(p[20:])
+		m[6] = binary.BigEndian.Uint32(p[24:])
+		m[7] = binary.BigEndian.Uint32(p[28:])
+		m[8] = binary.BigEndian.Uint32(p[32:])
+		m[9] = binary.BigEndian.Uint32(p[36:])
+		m[10] = binary.BigEndian.Uint32(p[40:])
+		m[11] = binary.BigEndian.Uint32(p[44:])
+		m[12] = binary.BigEndian.Uint32(p[48:])
+		m[13] = binary.BigEndian.Uint32(p[52:])
+		m[14] = binary.BigEndian.Uint32(p[56:])
+		m[15] = binary.BigEndian.Uint32(p[60:])
 
 		// Round 1.
 		v0 += m[0] ^ cst1
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[2] ^ cst3
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[4] ^ cst5
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[6] ^ cst7
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[5] ^ cst4
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[7] ^ cst6
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[3] ^ cst2
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[1] ^ cst0
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[8] ^ cst9
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[10] ^ cst11
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[12] ^ cst13
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[14] ^ cst15
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[13] ^ cst12
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[15] ^ cst14
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[11] ^ cst10
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[9] ^ cst8
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(1, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 2.
 		v0 += m[14] ^ cst10
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[4] ^ cst8
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[9] ^ cst15
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[13] ^ cst6
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[15] ^ cst9
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[6] ^ cst13
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[8] ^ cst4
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[10] ^ cst14
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[1] ^ cst12
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[0] ^ cst2
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[11] ^ cst7
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[5] ^ cst3
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[7] ^ cst11
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[3] ^ cst5
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[2] ^ cst0
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[12] ^ cst1
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(2, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 3.
 		v0 += m[11] ^ cst8
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[12] ^ cst0
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[5] ^ cst2
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[15] ^ cst13
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[2] ^ cst5
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[13] ^ cst15
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[0] ^ cst12
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[8] ^ cst11
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[10] ^ cst14
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[3] ^ cst6
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[7] ^ cst1
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[9] ^ cst4
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[1] ^ cst7
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[4] ^ cst9
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[6] ^ cst3
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[14] ^ cst10
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(3, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 4.
 		v0 += m[7] ^ cst9
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[3] ^ cst1
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[13] ^ cst12
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[11] ^ cst14
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[12] ^ cst13
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[14] ^ cst11
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[1] ^ cst3
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[9] ^ cst7
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[2] ^ cst6
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[5] ^ cst10
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[4] ^ cst0
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[15] ^ cst8
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[0] ^ cst4
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[8] ^ cst15
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[10] ^ cst5
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[6] ^ cst2
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(4, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 5.
 		v0 += m[9] ^ cst0
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[5] ^ cst7
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[2] ^ cst4
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[10] ^ cst15
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[4] ^ cst2
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[15] ^ cst10
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[7] ^ cst5
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[0] ^ cst9
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[14] ^ cst1
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[11] ^ cst12
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[6] ^ cst8
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[3] ^ cst13
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[8] ^ cst6
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[13] ^ cst3
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[12] ^ cst11
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[1] ^ cst14
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(5, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 6.
 		v0 += m[2] ^ cst12
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[6] ^ cst10
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[0] ^ cst11
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[8] ^ cst3
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[11] ^ cst0
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[3] ^ cst8
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[10] ^ cst6
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[12] ^ cst2
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[4] ^ cst13
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[7] ^ cst5
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[15] ^ cst14
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[1] ^ cst9
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[14] ^ cst15
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[9] ^ cst1
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[5] ^ cst7
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[13] ^ cst4
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(6, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 7.
 		v0 += m[12] ^ cst5
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[1] ^ cst15
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[14] ^ cst13
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[4] ^ cst10
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[13] ^ cst14
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[10] ^ cst4
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[15] ^ cst1
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[5] ^ cst12
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[0] ^ cst7
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[6] ^ cst3
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[9] ^ cst2
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[8] ^ cst11
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[2] ^ cst9
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[11] ^ cst8
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[3] ^ cst6
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[7] ^ cst0
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(7, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 8.
 		v0 += m[13] ^ cst11
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[7] ^ cst14
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[12] ^ cst1
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[3] ^ cst9
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[1] ^ cst12
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[9] ^ cst3
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[14] ^ cst7
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[11] ^ cst13
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[5] ^ cst0
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[15] ^ cst4
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[8] ^ cst6
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[2] ^ cst10
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[6] ^ cst8
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[10] ^ cst2
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[4] ^ cst15
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[0] ^ cst5
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(8, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 9.
 		v0 += m[6] ^ cst15
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[14] ^ cst9
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[11] ^ cst3
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[0] ^ cst8
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[3] ^ cst11
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[8] ^ cst0
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[9] ^ cst14
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[15] ^ cst6
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[12] ^ cst2
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[13] ^ cst7
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[1] ^ cst4
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[10] ^ cst5
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[4] ^ cst1
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[5] ^ cst10
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[7] ^ cst13
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[2] ^ cst12
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(9, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 10.
 		v0 += m[10] ^ cst2
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[8] ^ cst4
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[7] ^ cst6
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[1] ^ cst5
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[6] ^ cst7
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[5] ^ cst1
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[4] ^ cst8
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[2] ^ cst10
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[15] ^ cst11
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[9] ^ cst14
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[3] ^ cst12
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[13] ^ cst0
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[12] ^ cst3
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[0] ^ cst13
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[14] ^ cst9
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[11] ^ cst15
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(10, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 11.
 		v0 += m[0] ^ cst1
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[2] ^ cst3
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[4] ^ cst5
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[6] ^ cst7
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[5] ^ cst4
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[7] ^ cst6
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[3] ^ cst2
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[1] ^ cst0
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[8] ^ cst9
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[10] ^ cst11
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[12] ^ cst13
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[14] ^ cst15
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[13] ^ cst12
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[15] ^ cst14
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[11] ^ cst10
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[9] ^ cst8
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(11, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 12.
 		v0 += m[14] ^ cst10
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[4] ^ cst8
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[9] ^ cst15
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[13] ^ cst6
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[15] ^ cst9
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[6] ^ cst13
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[8] ^ cst4
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[10] ^ cst14
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[1] ^ cst12
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[0] ^ cst2
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[11] ^ cst7
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[5] ^ cst3
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[7] ^ cst11
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[3] ^ cst5
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[2] ^ cst0
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[12] ^ cst1
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(12, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 13.
 		v0 += m[11] ^ cst8
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[12] ^ cst0
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[5] ^ cst2
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[15] ^ cst13
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[2] ^ cst5
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[13] ^ cst15
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[0] ^ cst12
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[8] ^ cst11
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[10] ^ cst14
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[3] ^ cst6
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[7] ^ cst1
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[9] ^ cst4
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[1] ^ cst7
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[4] ^ cst9
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[6] ^ cst3
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[14] ^ cst10
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(13, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		// Round 14.
 		v0 += m[7] ^ cst9
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v1 += m[3] ^ cst1
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v2 += m[13] ^ cst12
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v3 += m[11] ^ cst14
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v2 += m[12] ^ cst13
 		v2 += v6
 		v14 ^= v2
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v10 += v14
 		v6 ^= v10
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v3 += m[14] ^ cst11
 		v3 += v7
 		v15 ^= v3
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v11 += v15
 		v7 ^= v11
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v1 += m[1] ^ cst3
 		v1 += v5
 		v13 ^= v1
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v9 += v13
 		v5 ^= v9
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
 		v0 += m[9] ^ cst7
 		v0 += v4
 		v12 ^= v0
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v8 += v12
 		v4 ^= v8
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v0 += m[2] ^ cst6
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-16) | v15>>16
+		v15 = rotr(v15, 16)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-12) | v5>>12
+		v5 = rotr(v5, 12)
 		v1 += m[5] ^ cst10
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-16) | v12>>16
+		v12 = rotr(v12, 16)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-12) | v6>>12
+		v6 = rotr(v6, 12)
 		v2 += m[4] ^ cst0
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-16) | v13>>16
+		v13 = rotr(v13, 16)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-12) | v7>>12
+		v7 = rotr(v7, 12)
 		v3 += m[15] ^ cst8
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-16) | v14>>16
+		v14 = rotr(v14, 16)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-12) | v4>>12
+		v4 = rotr(v4, 12)
 		v2 += m[0] ^ cst4
 		v2 += v7
 		v13 ^= v2
-		v13 = v13<<(32-8) | v13>>8
+		v13 = rotr(v13, 8)
 		v8 += v13
 		v7 ^= v8
-		v7 = v7<<(32-7) | v7>>7
+		v7 = rotr(v7, 7)
 		v3 += m[8] ^ cst15
 		v3 += v4
 		v14 ^= v3
-		v14 = v14<<(32-8) | v14>>8
+		v14 = rotr(v14, 8)
 		v9 += v14
 		v4 ^= v9
-		v4 = v4<<(32-7) | v4>>7
+		v4 = rotr(v4, 7)
 		v1 += m[10] ^ cst5
 		v1 += v6
 		v12 ^= v1
-		v12 = v12<<(32-8) | v12>>8
+		v12 = rotr(v12, 8)
 		v11 += v12
 		v6 ^= v11
-		v6 = v6<<(32-7) | v6>>7
+		v6 = rotr(v6, 7)
 		v0 += m[6] ^ cst2
 		v0 += v5
 		v15 ^= v0
-		v15 = v15<<(32-8) | v15>>8
+		v15 = rotr(v15, 8)
 		v10 += v15
 		v5 ^= v10
-		v5 = v5<<(32-7) | v5>>7
+		v5 = rotr(v5, 7)
+
+		if traceEnabled && traceRound != nil {
+			traceRound(14, [16]uint32{v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15})
+		}
 
 		h0 ^= v0 ^ v8 ^ s0
 		h1 ^= v1 ^ v9 ^ s1
@@ -1677,5 +1762,62 @@ func block(d *digest, p []uint8) {
 
 		p = p[BlockSize:]
 	}
+	d.t = uint64(t1)<<32 | uint64(t0)
 	d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7] = h0, h1, h2, h3, h4, h5, h6, h7
 }
+
+// sigma holds the message word permutations of the ten distinct rounds.
+var sigma = [10][16]uint8{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// u256 holds the constants indexed by sigma.
+var u256 = [16]uint32{
+	cst0, cst1, cst2, cst3, cst4, cst5, cst6, cst7,
+	cst8, cst9, cst10, cst11, cst12, cst13, cst14, cst15,
+}
+
+// Round applies round r (0 through 13) of the BLAKE-256 compression
+// function to the state v in place, using the message words m.
+// A full compression is the 14 rounds applied in order. Round is exported
+// so that the permutation can be tested in isolation. block does not use
+// it: with the rounds unrolled, the state stays in local variables rather
+// than in an array indexed through sigma, which makes hashing about 10%
+// faster on amd64. FuzzRound checks that both compute the same function.
+func Round(v *[16]uint32, m *[16]uint32, r int) {
+	if r < 0 || r > 13 {
+		panic("blake256: round out of range")
+	}
+	// Rounds 10 through 13 repeat the permutations of rounds 0 through 3.
+	s := &sigma[r%10]
+	g(v, m, s, 0, 4, 8, 12, 0)
+	g(v, m, s, 1, 5, 9, 13, 2)
+	g(v, m, s, 2, 6, 10, 14, 4)
+	g(v, m, s, 3, 7, 11, 15, 6)
+	g(v, m, s, 0, 5, 10, 15, 8)
+	g(v, m, s, 1, 6, 11, 12, 10)
+	g(v, m, s, 2, 7, 8, 13, 12)
+	g(v, m, s, 3, 4, 9, 14, 14)
+}
+
+// g is the G function mixing v[a], v[b], v[c], v[d] with the message
+// words and constants selected by s[i] and s[i+1].
+func g(v, m *[16]uint32, s *[16]uint8, a, b, c, d, i int) {
+	v[a] += v[b] + (m[s[i]] ^ u256[s[i+1]])
+	v[d] = rotr(v[d]^v[a], 16)
+	v[c] += v[d]
+	v[b] = rotr(v[b]^v[c], 12)
+	v[a] += v[b] + (m[s[i+1]] ^ u256[s[i]])
+	v[d] = rotr(v[d]^v[a], 8)
+	v[c] += v[d]
+	v[b] = rotr(v[b]^v[c], 7)
+}
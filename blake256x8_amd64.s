@@ -0,0 +1,2133 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// Eight-way BLAKE-256 compression using AVX-512 (F and VL).
+// Lane j of each YMM register holds a word of the j-th message.
+// State words v0..v15 are kept in Y0..Y15, Y16 is a temporary.
+//
+// h:   chain values, h[i][j] is word i of lane j (updated in place)
+// v:   initial values of v8..v15
+// m:   message words, m[i][j] is word i of lane j
+// cst: constants, each broadcast to 8 lanes
+
+// func block8AVX512(h *[8][8]uint32, v *[8][8]uint32, m *[16][8]uint32, cst *[16][8]uint32)
+TEXT ·block8AVX512(SB), NOSPLIT, $0-32
+	MOVQ h+0(FP), AX
+	MOVQ v+8(FP), BX
+	MOVQ m+16(FP), SI
+	MOVQ cst+24(FP), DI
+	VMOVDQU32 0(AX), Y0
+	VMOVDQU32 32(AX), Y1
+	VMOVDQU32 64(AX), Y2
+	VMOVDQU32 96(AX), Y3
+	VMOVDQU32 128(AX), Y4
+	VMOVDQU32 160(AX), Y5
+	VMOVDQU32 192(AX), Y6
+	VMOVDQU32 224(AX), Y7
+	VMOVDQU32 0(BX), Y8
+	VMOVDQU32 32(BX), Y9
+	VMOVDQU32 64(BX), Y10
+	VMOVDQU32 96(BX), Y11
+	VMOVDQU32 128(BX), Y12
+	VMOVDQU32 160(BX), Y13
+	VMOVDQU32 192(BX), Y14
+	VMOVDQU32 224(BX), Y15
+
+	// Round 1.
+	VMOVDQU32 0(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 32(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 64(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 96(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 128(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 160(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 192(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 224(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 256(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 288(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 320(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 352(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 384(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 416(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 448(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 480(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 2.
+	VMOVDQU32 448(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 320(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 128(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 256(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 288(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 480(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 416(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 192(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 32(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 384(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 0(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 64(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 352(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 224(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 160(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 96(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 3.
+	VMOVDQU32 352(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 256(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 384(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 0(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 160(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 64(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 480(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 416(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 320(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 448(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 96(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 192(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 224(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 32(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 288(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 128(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 4.
+	VMOVDQU32 224(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 288(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 96(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 32(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 416(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 384(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 352(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 448(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 64(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 192(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 160(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 320(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 128(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 0(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 480(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 256(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 5.
+	VMOVDQU32 288(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 0(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 160(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 224(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 64(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 128(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 320(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 480(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 448(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 32(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 352(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 384(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 192(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 256(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 96(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 416(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 6.
+	VMOVDQU32 64(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 384(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 192(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 320(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 0(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 352(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 256(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 96(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 128(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 416(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 224(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 160(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 480(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 448(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 32(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 288(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 7.
+	VMOVDQU32 384(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 160(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 32(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 480(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 448(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 416(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 128(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 320(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 0(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 224(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 192(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 96(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 288(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 64(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 256(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 352(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 8.
+	VMOVDQU32 416(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 352(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 224(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 448(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 384(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 32(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 96(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 288(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 160(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 0(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 480(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 128(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 256(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 192(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 64(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 320(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 9.
+	VMOVDQU32 192(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 480(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 448(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 288(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 352(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 96(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 0(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 256(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 384(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 64(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 416(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 224(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 32(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 128(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 320(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 160(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 10.
+	VMOVDQU32 320(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 64(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 256(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 128(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 224(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 192(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 32(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 160(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 480(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 352(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 288(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 448(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 96(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 384(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 416(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 0(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 11.
+	VMOVDQU32 0(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 32(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 64(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 96(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 128(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 160(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 192(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 224(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 256(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 288(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 320(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 352(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 384(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 416(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 448(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 480(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 12.
+	VMOVDQU32 448(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 320(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 128(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 256(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 288(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 480(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 416(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 192(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 32(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 384(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 0(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 64(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 352(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 224(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 160(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 96(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 13.
+	VMOVDQU32 352(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 256(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 384(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 0(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 160(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 64(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 480(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 416(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 320(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 448(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 96(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 192(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 224(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 32(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 288(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 128(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// Round 14.
+	VMOVDQU32 224(SI), Y16
+	VPXORD 288(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 288(SI), Y16
+	VPXORD 224(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y4, Y0, Y0
+	VPXORD Y0, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y8, Y8
+	VPXORD Y8, Y4, Y4
+	VPRORD $7, Y4, Y4
+	VMOVDQU32 96(SI), Y16
+	VPXORD 32(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 32(SI), Y16
+	VPXORD 96(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y5, Y1, Y1
+	VPXORD Y1, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y9, Y9
+	VPXORD Y9, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 416(SI), Y16
+	VPXORD 384(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 384(SI), Y16
+	VPXORD 416(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y6, Y2, Y2
+	VPXORD Y2, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y10, Y10
+	VPXORD Y10, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 352(SI), Y16
+	VPXORD 448(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 448(SI), Y16
+	VPXORD 352(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y7, Y3, Y3
+	VPXORD Y3, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y11, Y11
+	VPXORD Y11, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 64(SI), Y16
+	VPXORD 192(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $16, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $12, Y5, Y5
+	VMOVDQU32 192(SI), Y16
+	VPXORD 64(DI), Y16, Y16
+	VPADDD Y16, Y0, Y0
+	VPADDD Y5, Y0, Y0
+	VPXORD Y0, Y15, Y15
+	VPRORD $8, Y15, Y15
+	VPADDD Y15, Y10, Y10
+	VPXORD Y10, Y5, Y5
+	VPRORD $7, Y5, Y5
+	VMOVDQU32 160(SI), Y16
+	VPXORD 320(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $16, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $12, Y6, Y6
+	VMOVDQU32 320(SI), Y16
+	VPXORD 160(DI), Y16, Y16
+	VPADDD Y16, Y1, Y1
+	VPADDD Y6, Y1, Y1
+	VPXORD Y1, Y12, Y12
+	VPRORD $8, Y12, Y12
+	VPADDD Y12, Y11, Y11
+	VPXORD Y11, Y6, Y6
+	VPRORD $7, Y6, Y6
+	VMOVDQU32 128(SI), Y16
+	VPXORD 0(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $16, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $12, Y7, Y7
+	VMOVDQU32 0(SI), Y16
+	VPXORD 128(DI), Y16, Y16
+	VPADDD Y16, Y2, Y2
+	VPADDD Y7, Y2, Y2
+	VPXORD Y2, Y13, Y13
+	VPRORD $8, Y13, Y13
+	VPADDD Y13, Y8, Y8
+	VPXORD Y8, Y7, Y7
+	VPRORD $7, Y7, Y7
+	VMOVDQU32 480(SI), Y16
+	VPXORD 256(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $16, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $12, Y4, Y4
+	VMOVDQU32 256(SI), Y16
+	VPXORD 480(DI), Y16, Y16
+	VPADDD Y16, Y3, Y3
+	VPADDD Y4, Y3, Y3
+	VPXORD Y3, Y14, Y14
+	VPRORD $8, Y14, Y14
+	VPADDD Y14, Y9, Y9
+	VPXORD Y9, Y4, Y4
+	VPRORD $7, Y4, Y4
+
+	// h[i] ^= v[i] ^ v[i+8] (salt is zero).
+	VPXORD Y8, Y0, Y0
+	VPXORD 0(AX), Y0, Y0
+	VMOVDQU32 Y0, 0(AX)
+	VPXORD Y9, Y1, Y1
+	VPXORD 32(AX), Y1, Y1
+	VMOVDQU32 Y1, 32(AX)
+	VPXORD Y10, Y2, Y2
+	VPXORD 64(AX), Y2, Y2
+	VMOVDQU32 Y2, 64(AX)
+	VPXORD Y11, Y3, Y3
+	VPXORD 96(AX), Y3, Y3
+	VMOVDQU32 Y3, 96(AX)
+	VPXORD Y12, Y4, Y4
+	VPXORD 128(AX), Y4, Y4
+	VMOVDQU32 Y4, 128(AX)
+	VPXORD Y13, Y5, Y5
+	VPXORD 160(AX), Y5, Y5
+	VMOVDQU32 Y5, 160(AX)
+	VPXORD Y14, Y6, Y6
+	VPXORD 192(AX), Y6, Y6
+	VMOVDQU32 Y6, 192(AX)
+	VPXORD Y15, Y7, Y7
+	VPXORD 224(AX), Y7, Y7
+	VMOVDQU32 Y7, 224(AX)
+	VZEROUPPER
+	RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
@@ -0,0 +1,57 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build blake256compare
+
+// Benchmarks comparing BLAKE-256 with BLAKE2b and BLAKE3.
+// They require third-party packages, so they are only built with
+// the blake256compare tag:
+//
+//	go get golang.org/x/crypto/blake2b lukechampine.com/blake3
+//	go test -tags blake256compare -bench Compare
+
+package blake256
+
+import (
+	"hash"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+	"lukechampine.com/blake3"
+)
+
+var compareHashes = []struct {
+	name    string
+	newHash func() hash.Hash
+}{
+	{"BLAKE-256", New},
+	{"BLAKE2b-256", func() hash.Hash {
+		h, _ := blake2b.New256(nil)
+		return h
+	}},
+	{"BLAKE3", func() hash.Hash { return blake3.New(32, nil) }},
+}
+
+func benchmarkCompare(b *testing.B, size int) {
+	for _, c := range compareHashes {
+		b.Run(c.name, func(b *testing.B) {
+			h := c.newHash()
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				h.Reset()
+				h.Write(buf_in[:size])
+				_ = h.Sum(buf_out[0:0])
+			}
+		})
+	}
+}
+
+func BenchmarkCompare64(b *testing.B) { benchmarkCompare(b, 64) }
+
+func BenchmarkCompare1K(b *testing.B) { benchmarkCompare(b, 1024) }
+
+func BenchmarkCompare8K(b *testing.B) { benchmarkCompare(b, len(buf_in)) }
@@ -0,0 +1,18 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build amd64 && !purego
+
+package blake256
+
+// hasCycleCounter reports whether rdtsc is available.
+const hasCycleCounter = true
+
+// rdtsc returns the value of the time-stamp counter. It counts at
+// a constant reference rate, which may differ from the current core
+// clock rate. It is used by benchmarks to report cycles per byte.
+func rdtsc() uint64
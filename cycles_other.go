@@ -0,0 +1,14 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build !amd64 || purego
+
+package blake256
+
+const hasCycleCounter = false
+
+func rdtsc() uint64 { return 0 }
@@ -0,0 +1,43 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build blake256debug
+
+// Diagnostics for comparing intermediate state with other implementations.
+// Only available when built with the blake256debug tag.
+
+package blake256
+
+import "fmt"
+
+const traceEnabled = true
+
+// traceRound, if not nil, is called by block after each round with
+// the round number (1 to 14) and the state words v0..v15.
+var traceRound func(round int, v [16]uint32)
+
+// SetRoundTrace sets a function which is called after each round of
+// compression with the round number (1 to 14) and the internal state.
+// Passing nil disables tracing. It is intended for diagnostics only.
+func SetRoundTrace(fn func(round int, v [16]uint32)) {
+	traceRound = fn
+}
+
+// CompressedBytes returns the number of bytes, including padding, passed
+// to the compression function by this digest since it was created or reset.
+// Sum compresses the padding in a copy of the digest, so it doesn't change
+// the result. It is intended for diagnostics only.
+func (d *Digest) CompressedBytes() uint64 {
+	return uint64(d.blocks) * BlockSize
+}
+
+// Dump returns the internal state of the digest formatted in hex.
+// It is intended for diagnostics only.
+func (d *Digest) Dump() string {
+	return fmt.Sprintf("h: %08x\ns: %08x\nt: %016x\nnx: %d\nnullt: %v\nx: %x\n",
+		d.h, d.s, d.t, d.nx, d.nullt, d.x[:d.nx])
+}
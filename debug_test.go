@@ -0,0 +1,90 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build blake256debug
+
+package blake256
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	h := New().(*Digest)
+	h.Write([]byte("BLAKE"))
+	s := h.Dump()
+	for _, want := range []string{"h: [6a09e667 ", "t: 0000000000000000", "nx: 5", "x: 424c414b45"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Dump: expected %q in\n%s", want, s)
+		}
+	}
+}
+
+func TestRoundTrace(t *testing.T) {
+	var rounds []int
+	SetRoundTrace(func(round int, v [16]uint32) {
+		rounds = append(rounds, round)
+	})
+	defer SetRoundTrace(nil)
+	Sum256([]byte("BLAKE"))
+	if len(rounds) != 14 {
+		t.Fatalf("expected 14 rounds, got %d", len(rounds))
+	}
+	for i, r := range rounds {
+		if r != i+1 {
+			t.Errorf("expected round %d, got %d", i+1, r)
+		}
+	}
+}
+
+func TestCompressedBytes(t *testing.T) {
+	t.Parallel()
+	data := make([]byte, 200)
+	tests := []struct {
+		writes []int
+		want   uint64 // bytes compressed before Sum
+	}{
+		{[]int{63}, 0},
+		{[]int{63, 1}, 64},
+		{[]int{64}, 64},
+		{[]int{65}, 64},
+		{[]int{1, 1, 62}, 64},
+		{[]int{32, 32, 32, 32}, 128},
+		{[]int{1, 127}, 128},
+		{[]int{1, 128}, 128},
+		{[]int{0, 0, 200}, 192},
+	}
+	for _, tt := range tests {
+		h := New().(*Digest)
+		for _, n := range tt.writes {
+			h.Write(data[:n])
+		}
+		if got := h.CompressedBytes(); got != tt.want {
+			t.Errorf("%v: expected %d bytes compressed, got %d", tt.writes, tt.want, got)
+		}
+		h.Sum(nil)
+		if got := h.CompressedBytes(); got != tt.want {
+			t.Errorf("%v: Sum changed bytes compressed from %d to %d", tt.writes, tt.want, got)
+		}
+		h.Reset()
+		if got := h.CompressedBytes(); got != 0 {
+			t.Errorf("%v: expected 0 bytes compressed after Reset, got %d", tt.writes, got)
+		}
+	}
+
+	// Finalization compresses one or two padding blocks.
+	for _, tt := range []struct{ n, want int }{{0, 64}, {55, 64}, {56, 128}, {64, 64}} {
+		h := New().(*Digest)
+		h.Write(data[:tt.n])
+		d := *h
+		d.checkSum()
+		if got := d.CompressedBytes() - h.CompressedBytes(); got != uint64(tt.want) {
+			t.Errorf("Sum after %d bytes: expected %d bytes compressed, got %d", tt.n, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// domainDigest is a digest which restores the state after absorbing
+// the domain prefix on Reset. The digest is not embedded, so that its
+// other reset methods can't drop the prefix.
+type domainDigest struct {
+	h    Digest
+	init Digest
+}
+
+func (d *domainDigest) Reset() { d.h = d.init }
+
+func (d *domainDigest) Size() int { return d.h.Size() }
+
+func (d *domainDigest) BlockSize() int { return BlockSize }
+
+func (d *domainDigest) Write(p []byte) (int, error) { return d.h.Write(p) }
+
+func (d *domainDigest) Sum(in []byte) []byte { return d.h.Sum(in) }
+
+// NewDomainSeparated returns a new hash.Hash computing the BLAKE-256
+// checksum of the message prefixed with the domain.
+//
+// The prefix consists of the 64-bit big-endian length of domain in bytes,
+// followed by domain and zero bytes up to the next multiple of BlockSize,
+// so that the prefix is compressed once and different domains never
+// produce the same input. The result is equal to Sum256(prefix || message).
+func NewDomainSeparated(domain []byte) hash.Hash {
+	n := (8 + len(domain) + BlockSize - 1) &^ (BlockSize - 1)
+	prefix := make([]byte, n)
+	binary.BigEndian.PutUint64(prefix, uint64(len(domain)))
+	copy(prefix[8:], domain)
+
+	d := &domainDigest{}
+	d.init.hashSize = 256
+	d.init.Reset()
+	d.init.Write(prefix)
+	d.Reset()
+	return d
+}
@@ -0,0 +1,45 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"crypto/hmac"
+	"errors"
+)
+
+// HKDF derives length bytes of key material from secret, salt and info
+// according to RFC 5869, using HMAC-BLAKE-256 as the PRF.
+//
+// The output length is limited to 255*Size bytes; for larger lengths
+// an error is returned.
+func HKDF(secret, salt, info []byte, length int) ([]byte, error) {
+	if length < 0 || length > 255*Size {
+		return nil, errors.New("blake256: invalid HKDF output length")
+	}
+	if salt == nil {
+		salt = make([]byte, Size)
+	}
+	// Extract.
+	ext := hmac.New(New, salt)
+	ext.Write(secret)
+	prk := ext.Sum(nil)
+
+	// Expand.
+	exp := hmac.New(New, prk)
+	out := make([]byte, 0, length+Size)
+	var prev []byte
+	for ctr := byte(1); len(out) < length; ctr++ {
+		exp.Reset()
+		exp.Write(prev)
+		exp.Write(info)
+		exp.Write([]byte{ctr})
+		prev = exp.Sum(out[len(out):len(out)])
+		out = out[:len(out)+Size]
+	}
+	return out[:length], nil
+}
@@ -0,0 +1,62 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"errors"
+	"hash"
+)
+
+// ErrLimitExceeded is returned by Write of a hash created with NewLimited
+// when the total input size exceeds the limit.
+var ErrLimitExceeded = errors.New("blake256: input size limit exceeded")
+
+// limitedDigest is a digest which accepts at most max bytes.
+// The digest is not embedded, so that its other write methods
+// can't be used to bypass the limit.
+type limitedDigest struct {
+	h   Digest
+	max int64 // maximum number of bytes to accept
+	n   int64 // number of bytes written
+}
+
+func (d *limitedDigest) Reset() {
+	d.h.Reset()
+	d.n = 0
+}
+
+func (d *limitedDigest) Size() int { return d.h.Size() }
+
+func (d *limitedDigest) BlockSize() int { return BlockSize }
+
+func (d *limitedDigest) Sum(in []byte) []byte { return d.h.Sum(in) }
+
+func (d *limitedDigest) Write(p []byte) (int, error) {
+	if int64(len(p)) > d.max-d.n {
+		n, _ := d.h.Write(p[:d.max-d.n])
+		d.n += int64(n)
+		return n, ErrLimitExceeded
+	}
+	n, err := d.h.Write(p)
+	d.n += int64(n)
+	return n, err
+}
+
+// NewLimited returns a new hash.Hash computing the BLAKE-256 checksum of
+// at most maxBytes bytes of input. Once the total size of written data
+// would exceed maxBytes, Write absorbs only the bytes up to the limit and
+// returns ErrLimitExceeded; callers should treat the checksum as invalid.
+func NewLimited(maxBytes int64) (hash.Hash, error) {
+	if maxBytes < 0 {
+		return nil, errors.New("blake256: negative limit")
+	}
+	d := &limitedDigest{max: maxBytes}
+	d.h.hashSize = 256
+	d.Reset()
+	return d, nil
+}
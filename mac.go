@@ -0,0 +1,120 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+// MAC computes HMAC-BLAKE-256. Unlike hmac.New, it can be rekeyed
+// without allocating. It implements hash.Hash.
+type MAC struct {
+	inner Digest
+	ipad  [BlockSize]byte
+	opad  [BlockSize]byte
+}
+
+// NewMACKeyed returns a new MAC using the given key.
+func NewMACKeyed(key []byte) *MAC {
+	m := new(MAC)
+	m.Rekey(key)
+	return m
+}
+
+// Rekey sets a new key and resets the MAC.
+func (m *MAC) Rekey(key []byte) {
+	if len(key) > BlockSize {
+		k := Sum256(key)
+		key = k[:]
+	}
+	m.ipad = [BlockSize]byte{}
+	copy(m.ipad[:], key)
+	m.opad = m.ipad
+	for i := range m.ipad {
+		m.ipad[i] ^= 0x36
+		m.opad[i] ^= 0x5c
+	}
+	m.Reset()
+}
+
+// Reset resets the MAC to its initial keyed state.
+func (m *MAC) Reset() {
+	m.inner.hashSize = 256
+	m.inner.Reset()
+	m.inner.Write(m.ipad[:])
+}
+
+// Write adds more data to the running MAC. Like Digest.Write, it returns
+// ErrTooLong if the message exceeds the maximum message length.
+func (m *MAC) Write(p []byte) (int, error) { return m.inner.Write(p) }
+
+// Sum appends the current MAC to in and returns the resulting slice.
+func (m *MAC) Sum(in []byte) []byte {
+	var outer Digest
+	outer.hashSize = 256
+	outer.Reset()
+	outer.Write(m.opad[:])
+	outer.Write(m.inner.Sum(nil))
+	return outer.Sum(in)
+}
+
+// Size returns the MAC size in bytes.
+func (m *MAC) Size() int { return Size }
+
+// BlockSize returns the block size of the underlying hash function.
+func (m *MAC) BlockSize() int { return BlockSize }
+
+// HMACTag returns HMAC-BLAKE-256 of msg with the given key truncated to
+// tagLen bytes, which must be between 1 and Size. Tags are the leftmost
+// bytes of the full MAC, as specified by RFC 2104. Verify truncated tags by
+// computing HMACTag with the same tagLen and comparing them with
+// hmac.Equal; shorter tags are easier to forge by guessing.
+func HMACTag(key, msg []byte, tagLen int) ([]byte, error) {
+	if tagLen < 1 || tagLen > Size {
+		return nil, errors.New("blake256: invalid tag length")
+	}
+	var m MAC
+	m.Rekey(key)
+	m.Write(msg)
+	return m.Sum(nil)[:tagLen], nil
+}
+
+// MACVerifier computes HMAC-BLAKE-256 of data written through it and
+// verifies it against an expected tag.
+type MACVerifier struct {
+	m MAC
+	w io.Writer
+}
+
+// NewMACVerifier returns a new MACVerifier using the given key. Data
+// written to it is also written to w, unless w is nil.
+func NewMACVerifier(key []byte, w io.Writer) *MACVerifier {
+	v := &MACVerifier{w: w}
+	v.m.Rekey(key)
+	return v
+}
+
+// Write writes p to the underlying writer and adds it to the MAC.
+// Only the bytes accepted by the underlying writer are authenticated.
+func (v *MACVerifier) Write(p []byte) (n int, err error) {
+	if v.w != nil {
+		n, err = v.w.Write(p)
+		p = p[:n]
+	}
+	v.m.Write(p)
+	return len(p), err
+}
+
+// VerifyTag reports whether expected is the MAC of the data written so
+// far. The comparison is performed in constant time.
+func (v *MACVerifier) VerifyTag(expected []byte) bool {
+	var tag [Size]byte
+	return subtle.ConstantTimeCompare(v.m.Sum(tag[:0]), expected) == 1
+}
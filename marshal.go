@@ -0,0 +1,94 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	magic224 = "blk\x02"
+	magic256 = "blk\x03"
+
+	// magic, h, s, t, x, nx, nbits, blocks
+	marshaledSize = len(magic256) + 8*4 + 4*4 + 8 + BlockSize + 1 + 1 + 8
+)
+
+// MarshalBinary returns the internal state of the digest.
+// Block tweak function is not included.
+func (d *Digest) MarshalBinary() ([]byte, error) {
+	return d.AppendBinary(make([]byte, 0, marshaledSize))
+}
+
+// AppendBinary appends the internal state of the digest to b.
+// Block tweak function is not included.
+func (d *Digest) AppendBinary(b []byte) ([]byte, error) {
+	if d.hashSize == 224 {
+		b = append(b, magic224...)
+	} else {
+		b = append(b, magic256...)
+	}
+	for _, v := range d.h {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	for _, v := range d.s {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	b = binary.BigEndian.AppendUint64(b, d.t)
+	b = append(b, d.x[:]...)
+	b = append(b, byte(d.nx), byte(d.nbits))
+	b = binary.BigEndian.AppendUint64(b, uint64(d.blocks))
+	return b, nil
+}
+
+// UnmarshalBinary restores the internal state of the digest
+// from the result of MarshalBinary.
+func (d *Digest) UnmarshalBinary(b []byte) error {
+	if len(b) < len(magic256) {
+		return errors.New("blake256: invalid hash state identifier")
+	}
+	var hashSize int
+	switch string(b[:len(magic256)]) {
+	case magic224:
+		hashSize = 224
+	case magic256:
+		hashSize = 256
+	default:
+		return errors.New("blake256: invalid hash state identifier")
+	}
+	if len(b) != marshaledSize {
+		return errors.New("blake256: invalid hash state size")
+	}
+	// The buffer is full only when its last byte is partial,
+	// which writeBits leaves for finalization.
+	nx, nbits := int(b[marshaledSize-10]), int(b[marshaledSize-9])
+	if nbits >= 8 || nx > BlockSize ||
+		nx == BlockSize && nbits == 0 || nx == 0 && nbits != 0 {
+		return errors.New("blake256: invalid hash state")
+	}
+	d.hashSize = hashSize
+	b = b[len(magic256):]
+	for i := range d.h {
+		d.h[i] = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	for i := range d.s {
+		d.s[i] = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	d.t = binary.BigEndian.Uint64(b)
+	b = b[8:]
+	b = b[copy(d.x[:], b):]
+	d.nx = nx
+	d.nbits = nbits
+	d.blocks = int(binary.BigEndian.Uint64(b[2:]))
+	d.nullt = false
+	d.closed = false
+	return nil
+}
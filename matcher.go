@@ -0,0 +1,43 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "crypto/subtle"
+
+// Matcher verifies streamed data against an expected checksum.
+type Matcher struct {
+	d        Digest
+	expected []byte
+}
+
+// NewMatcher returns a new Matcher for the expected checksum. If expected
+// is Size224 bytes long, BLAKE-224 is used, otherwise BLAKE-256.
+func NewMatcher(expected []byte) *Matcher {
+	m := &Matcher{expected: append([]byte(nil), expected...)}
+	if len(expected) == Size224 {
+		m.d.hashSize = 224
+	} else {
+		m.d.hashSize = 256
+	}
+	m.d.Reset()
+	return m
+}
+
+// Write adds more data to the stream being verified. Like Digest.Write,
+// it returns ErrTooLong if the stream exceeds the maximum message length.
+func (m *Matcher) Write(p []byte) (int, error) {
+	return m.d.Write(p)
+}
+
+// Verify reports whether the checksum of data written so far equals
+// the expected checksum. The comparison is performed in constant time.
+func (m *Matcher) Verify() bool {
+	d := m.d
+	sum := d.checkSum()
+	return subtle.ConstantTimeCompare(sum[:d.Size()], m.expected) == 1
+}
@@ -0,0 +1,50 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "crypto/subtle"
+
+// Domain separation prefixes for Merkle tree hashing, as in RFC 6962.
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// VerifyMerkleProof reports whether proof shows that leaf is included in
+// the Merkle tree with the given root.
+//
+// Leaves are hashed as BLAKE-256(0x00 || leaf) and interior nodes as
+// BLAKE-256(0x01 || left || right). Starting with the leaf hash, each
+// proof element is the sibling of the current node, and directions[i]
+// reports whether proof[i] is the right sibling (true) or the left one
+// (false). proof and directions must have the same length. The computed
+// root is compared with root in constant time.
+func VerifyMerkleProof(leaf []byte, proof [][]byte, directions []bool, root []byte) bool {
+	if len(proof) != len(directions) {
+		return false
+	}
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	d.Write([]byte{merkleLeafPrefix})
+	d.Write(leaf)
+	sum := d.checkSum()
+	for i, sibling := range proof {
+		d.Reset()
+		d.Write([]byte{merkleNodePrefix})
+		if directions[i] {
+			d.Write(sum[:])
+			d.Write(sibling)
+		} else {
+			d.Write(sibling)
+			d.Write(sum[:])
+		}
+		sum = d.checkSum()
+	}
+	return subtle.ConstantTimeCompare(sum[:], root) == 1
+}
@@ -0,0 +1,16 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package blake256
+
+// Sum256Mmap returns the BLAKE-256 checksum of the file at path.
+// On this platform memory mapping is not supported, so the file is streamed.
+func Sum256Mmap(path string) (Hash256, error) {
+	return SumFile256(path)
+}
@@ -0,0 +1,45 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package blake256
+
+import (
+	"os"
+	"syscall"
+)
+
+// Sum256Mmap returns the BLAKE-256 checksum of the file at path.
+// The file is memory-mapped and hashed in a single Write.
+func Sum256Mmap(path string) (Hash256, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Hash256{}, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return Hash256{}, err
+	}
+	size := fi.Size()
+	if size == 0 {
+		return Sum256(nil), nil
+	}
+	if int64(int(size)) != size {
+		return SumFile256(path)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return Hash256{}, err
+	}
+	sum := Sum256(data)
+	if err := syscall.Munmap(data); err != nil {
+		return Hash256{}, err
+	}
+	return sum, nil
+}
@@ -0,0 +1,73 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "errors"
+
+// OrderedHasher computes BLAKE-256 checksum of data written at arbitrary
+// offsets, in offset order. Segments that can't be hashed yet because
+// of a gap before them are buffered until the gap is filled.
+type OrderedHasher struct {
+	h       Digest
+	off     int64            // offset of the next byte to hash
+	pending map[int64][]byte // buffered segments by offset
+}
+
+// NewOrderedHasher returns a new OrderedHasher.
+func NewOrderedHasher() *OrderedHasher {
+	o := &OrderedHasher{pending: make(map[int64][]byte)}
+	o.h.hashSize = 256
+	o.h.Reset()
+	return o
+}
+
+// WriteAt adds p at offset off. It returns an error if off is negative
+// or if the segment overlaps data that has already been written.
+// WriteAt doesn't retain p.
+func (o *OrderedHasher) WriteAt(p []byte, off int64) error {
+	if off < 0 {
+		return errors.New("blake256: negative offset")
+	}
+	if len(p) == 0 {
+		return nil
+	}
+	end := off + int64(len(p))
+	if off < o.off {
+		return errors.New("blake256: segment overlaps written data")
+	}
+	for po, pp := range o.pending {
+		if off < po+int64(len(pp)) && po < end {
+			return errors.New("blake256: segment overlaps written data")
+		}
+	}
+	if off != o.off {
+		o.pending[off] = append([]byte(nil), p...)
+		return nil
+	}
+	o.h.Write(p)
+	o.off = end
+	for {
+		pp, ok := o.pending[o.off]
+		if !ok {
+			return nil
+		}
+		delete(o.pending, o.off)
+		o.h.Write(pp)
+		o.off += int64(len(pp))
+	}
+}
+
+// Sum appends the checksum of the data written so far to in and returns
+// the resulting slice. It returns an error if there are gaps in the data,
+// that is, if some segments couldn't be hashed yet.
+func (o *OrderedHasher) Sum(in []byte) ([]byte, error) {
+	if len(o.pending) != 0 {
+		return nil, errors.New("blake256: gap in written data")
+	}
+	return o.h.Sum(in), nil
+}
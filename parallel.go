@@ -0,0 +1,94 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// SumParallel256 reads r until EOF, splits the data into chunks of
+// chunkSize bytes (the last chunk may be shorter) and hashes them with
+// BLAKE-256 using the given number of worker goroutines. It returns the
+// BLAKE-256 checksum of the concatenation of the chunk checksums in input
+// order, so the result doesn't depend on scheduling, but depends on
+// chunkSize. Note that it differs from the checksum of the data itself.
+// Empty input has no chunks and results in Sum256(nil).
+func SumParallel256(r io.Reader, chunkSize, workers int) (Hash256, error) {
+	if chunkSize <= 0 || workers <= 0 {
+		return Hash256{}, errors.New("blake256: invalid chunk size or number of workers")
+	}
+	type job struct {
+		i    int
+		data []byte
+	}
+	type result struct {
+		i   int
+		sum Hash256
+	}
+	jobs := make(chan job, workers)
+	results := make(chan result, workers)
+
+	var readErr error
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			buf := make([]byte, chunkSize)
+			n, err := io.ReadFull(r, buf)
+			if n > 0 {
+				jobs <- job{i, buf[:n]}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return
+			}
+			if err != nil {
+				readErr = err
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- result{j.i, Sum256(j.data)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Fold chunk checksums in order.
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	pending := make(map[int]Hash256)
+	next := 0
+	for res := range results {
+		pending[res.i] = res.sum
+		for {
+			sum, ok := pending[next]
+			if !ok {
+				break
+			}
+			d.Write(sum[:])
+			delete(pending, next)
+			next++
+		}
+	}
+	if readErr != nil {
+		return Hash256{}, readErr
+	}
+	return d.checkSum(), nil
+}
@@ -0,0 +1,50 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "io"
+
+type prng struct {
+	seeded Digest     // state after writing seed
+	ctr    uint64     // number of the next output block
+	buf    [Size]byte // current output block
+	n      int        // number of unread bytes at the end of buf
+}
+
+// NewPRNG returns a reader producing a deterministic stream of
+// pseudorandom bytes from seed. The stream is
+//
+//	BLAKE-256(seed || 0) || BLAKE-256(seed || 1) || ...
+//
+// where the counter is encoded as 8 big-endian bytes. Read never returns
+// an error. The same seed always produces the same stream, which makes
+// it suitable for generating reproducible test data.
+func NewPRNG(seed []byte) io.Reader {
+	r := new(prng)
+	r.seeded.hashSize = 256
+	r.seeded.Reset()
+	r.seeded.Write(seed)
+	return r
+}
+
+func (r *prng) Read(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if r.n == 0 {
+			d := r.seeded
+			d.WriteUint64(r.ctr)
+			r.buf = d.checkSum()
+			r.ctr++
+			r.n = Size
+		}
+		c := copy(p, r.buf[Size-r.n:])
+		r.n -= c
+		p = p[c:]
+	}
+	return n, nil
+}
@@ -0,0 +1,62 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "errors"
+
+// ResumableHasher computes BLAKE-256 checksum of data that arrives in
+// parts, such as a resumable upload. Its state can be saved with
+// MarshalBinary between parts and restored with UnmarshalBinary.
+type ResumableHasher struct {
+	h Digest
+}
+
+// NewResumableHasher returns a new ResumableHasher.
+func NewResumableHasher() *ResumableHasher {
+	r := new(ResumableHasher)
+	r.h.hashSize = 256
+	r.h.Reset()
+	return r
+}
+
+// Append adds p to the hashed data.
+func (r *ResumableHasher) Append(p []byte) {
+	r.h.Write(p)
+}
+
+// Offset returns the number of bytes appended so far, which is the offset
+// at which the next part must start.
+func (r *ResumableHasher) Offset() int64 {
+	return int64(r.h.t>>3) + int64(r.h.nx)
+}
+
+// PartialSum returns the checksum of the data appended so far.
+// More data can be appended afterwards.
+func (r *ResumableHasher) PartialSum() Hash256 {
+	d := r.h
+	return d.checkSum()
+}
+
+// MarshalBinary returns the state of the hasher.
+func (r *ResumableHasher) MarshalBinary() ([]byte, error) {
+	return r.h.MarshalBinary()
+}
+
+// UnmarshalBinary restores the state of the hasher from the result of
+// MarshalBinary.
+func (r *ResumableHasher) UnmarshalBinary(b []byte) error {
+	var d Digest
+	if err := d.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	if d.hashSize != 256 || d.nbits != 0 {
+		return errors.New("blake256: invalid resumable hasher state")
+	}
+	r.h = d
+	return nil
+}
@@ -0,0 +1,23 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build !blake256manualrotate
+
+package blake256
+
+import "math/bits"
+
+// rotr rotates x right by n bits.
+//
+// Building with the blake256manualrotate tag replaces it with a version
+// using shifts, for comparing the code generated for both forms; see
+// BenchmarkRotate. On amd64 both compile to the same rotate instruction
+// and perform the same, so bits.RotateLeft32 is the default for all
+// architectures. Other architectures haven't been measured.
+func rotr(x uint32, n int) uint32 {
+	return bits.RotateLeft32(x, -n)
+}
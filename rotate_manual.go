@@ -0,0 +1,15 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build blake256manualrotate
+
+package blake256
+
+// rotr rotates x right by n bits, which must be between 1 and 31.
+func rotr(x uint32, n int) uint32 {
+	return x>>n | x<<(32-n)
+}
@@ -0,0 +1,78 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"bytes"
+	"errors"
+)
+
+type katVector struct {
+	hashSize int    // 224 or 256
+	inLen    int    // number of zero bytes to hash
+	out      []byte // expected checksum
+}
+
+// Known answers from the BLAKE specification.
+var selfTestVectors = []katVector{
+	{256, 1, []byte{
+		0x0c, 0xe8, 0xd4, 0xef, 0x4d, 0xd7, 0xcd, 0x8d,
+		0x62, 0xdf, 0xde, 0xd9, 0xd4, 0xed, 0xb0, 0xa7,
+		0x74, 0xae, 0x6a, 0x41, 0x92, 0x9a, 0x74, 0xda,
+		0x23, 0x10, 0x9e, 0x8f, 0x11, 0x13, 0x9c, 0x87}},
+	{256, 72, []byte{
+		0xd4, 0x19, 0xba, 0xd3, 0x2d, 0x50, 0x4f, 0xb7,
+		0xd4, 0x4d, 0x46, 0x0c, 0x42, 0xc5, 0x59, 0x3f,
+		0xe5, 0x44, 0xfa, 0x4c, 0x13, 0x5d, 0xec, 0x31,
+		0xe2, 0x1b, 0xd9, 0xab, 0xdc, 0xc2, 0x2d, 0x41}},
+	{224, 1, []byte{
+		0x45, 0x04, 0xcb, 0x03, 0x14, 0xfb, 0x2a, 0x4f,
+		0x7a, 0x69, 0x2e, 0x69, 0x6e, 0x48, 0x79, 0x12,
+		0xfe, 0x3f, 0x24, 0x68, 0xfe, 0x31, 0x2c, 0x73,
+		0xa5, 0x27, 0x8e, 0xc5}},
+	{224, 72, []byte{
+		0xf5, 0xaa, 0x00, 0xdd, 0x1c, 0xb8, 0x47, 0xe3,
+		0x14, 0x03, 0x72, 0xaf, 0x7b, 0x5c, 0x46, 0xb4,
+		0x88, 0x8d, 0x82, 0xc8, 0xc0, 0xa9, 0x17, 0x91,
+		0x3c, 0xfb, 0x5d, 0x04}},
+}
+
+// SelfTest runs known-answer tests for BLAKE-256 and BLAKE-224 and returns
+// an error if the implementation produces a wrong result. BLAKE-256 vectors
+// are also checked with the implementation currently selected for Sum256x8.
+func SelfTest() error {
+	return selfTest(selfTestVectors)
+}
+
+func selfTest(vectors []katVector) error {
+	var zero [72]byte
+	impl := x8impl.Load()
+	for _, v := range vectors {
+		var h = New()
+		if v.hashSize == 224 {
+			h = New224()
+		}
+		h.Write(zero[:v.inLen])
+		if !bytes.Equal(h.Sum(nil), v.out) {
+			return errors.New("blake256: self-test failed")
+		}
+		if v.hashSize != 256 {
+			continue
+		}
+		var in [8][]byte
+		for i := range in {
+			in[i] = zero[:v.inLen]
+		}
+		for _, out := range impl.sum(&in) {
+			if !bytes.Equal(out[:], v.out) {
+				return errors.New("blake256: self-test failed for " + impl.name + " Sum256x8")
+			}
+		}
+	}
+	return nil
+}
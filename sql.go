@@ -0,0 +1,56 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// Checksum is a BLAKE-256 or BLAKE-224 checksum that can be stored in
+// a database. It implements driver.Valuer and sql.Scanner, and is stored
+// as raw bytes.
+type Checksum []byte
+
+// Checksum returns the current checksum of d as a Checksum.
+// The state of the digest is not changed.
+func (d *Digest) Checksum() Checksum {
+	return d.Sum(nil)
+}
+
+// Value implements driver.Valuer.
+func (c Checksum) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return []byte(c), nil
+}
+
+// Scan implements sql.Scanner. It accepts []byte and string values of
+// Size or Size224 bytes, and nil, which sets c to nil.
+func (c *Checksum) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("blake256: cannot scan %T into Checksum", src)
+	}
+	if len(b) != Size && len(b) != Size224 {
+		return errors.New("blake256: invalid checksum length")
+	}
+	// Drivers may reuse the source buffer.
+	*c = append(Checksum(nil), b...)
+	return nil
+}
@@ -0,0 +1,269 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"hash"
+	"io"
+	"os"
+)
+
+// streamBufSize is the size of buffer used for streaming readers.
+// It is a multiple of BlockSize.
+const streamBufSize = 64 * BlockSize
+
+// SumContext256 returns the BLAKE-256 checksum of data read from r until EOF.
+// It checks ctx between reads and returns ctx.Err() once ctx is done.
+func SumContext256(ctx context.Context, r io.Reader) (Hash256, error) {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	var buf [streamBufSize]byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return Hash256{}, err
+		}
+		n, err := r.Read(buf[:])
+		d.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Hash256{}, err
+		}
+	}
+	return d.checkSum(), nil
+}
+
+// SumCopy copies from src to dst until either EOF is reached on src or an
+// error occurs, and returns the BLAKE-256 checksum of the copied data along
+// with the number of bytes copied. The checksum is computed in the same pass.
+func SumCopy(dst io.Writer, src io.Reader) (Hash256, int64, error) {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	n, err := io.Copy(io.MultiWriter(dst, &d), src)
+	if err != nil {
+		return Hash256{}, n, err
+	}
+	return d.checkSum(), n, nil
+}
+
+// SumCount256 returns the BLAKE-256 checksum of data read from r until EOF
+// and the number of bytes read. On error, it returns the number of bytes
+// read before the error.
+func SumCount256(r io.Reader) (Hash256, int64, error) {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	n, err := d.readFrom(r)
+	if err != nil {
+		return Hash256{}, n, err
+	}
+	return d.checkSum(), n, nil
+}
+
+// SumLines256 returns the BLAKE-256 checksum of data read from r until EOF
+// and the number of newline ('\n') bytes in it. A final line without
+// a trailing newline is not counted, as with wc -l.
+func SumLines256(r io.Reader) (Hash256, int, error) {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	var buf [streamBufSize]byte
+	lines := 0
+	for {
+		n, err := r.Read(buf[:])
+		d.Write(buf[:n])
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Hash256{}, lines, err
+		}
+	}
+	return d.checkSum(), lines, nil
+}
+
+// SumGzip256 returns the BLAKE-256 checksum of the decompressed contents
+// of the gzip stream read from r. Concatenated gzip members are
+// decompressed as one stream. It returns an error if the stream is not
+// valid gzip or fails its checksum.
+func SumGzip256(r io.Reader) (Hash256, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return Hash256{}, err
+	}
+	defer zr.Close()
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	if _, err := d.readFrom(zr); err != nil {
+		return Hash256{}, err
+	}
+	return d.checkSum(), nil
+}
+
+// SumSection256 returns the BLAKE-256 checksum of length bytes read from r
+// starting at offset off. It returns io.ErrUnexpectedEOF if r ends before
+// the end of the section. Since it uses only ReadAt, it can be called
+// concurrently for different sections of the same file.
+func SumSection256(r io.ReaderAt, off, length int64) (Hash256, error) {
+	if off < 0 || length < 0 {
+		return Hash256{}, errors.New("blake256: invalid section")
+	}
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	var buf [streamBufSize]byte
+	for length > 0 {
+		n := int64(len(buf))
+		if length < n {
+			n = length
+		}
+		nr, err := r.ReadAt(buf[:n], off)
+		if int64(nr) < n {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return Hash256{}, err
+		}
+		d.Write(buf[:n])
+		off += n
+		length -= n
+	}
+	return d.checkSum(), nil
+}
+
+// NewMultiWriter returns a writer that writes data to all hashers, so that
+// checksums of the same stream with different hash functions, such as
+// BLAKE-256 and SHA-256, can be computed in one pass, for example, with
+// io.Copy. Like io.MultiWriter, it stops at the first hasher that returns
+// an error, such as ErrLimitExceeded from a hash returned by NewLimited,
+// and returns that error.
+func NewMultiWriter(hashers ...hash.Hash) io.Writer {
+	w := make([]io.Writer, len(hashers))
+	for i, h := range hashers {
+		w[i] = h
+	}
+	return io.MultiWriter(w...)
+}
+
+// readFrom writes data read from r until EOF into d, using a buffer
+// aligned to BlockSize. It returns the number of bytes written.
+func (d *Digest) readFrom(r io.Reader) (n int64, err error) {
+	var buf [streamBufSize]byte
+	for {
+		nr, er := r.Read(buf[:])
+		d.Write(buf[:nr])
+		n += int64(nr)
+		if er == io.EOF {
+			return n, nil
+		}
+		if er != nil {
+			return n, er
+		}
+	}
+}
+
+// Absorb writes data read from r until EOF or error into the digest.
+// It returns the number of bytes written and any error except io.EOF
+// encountered while reading.
+func (d *Digest) Absorb(r io.Reader) (int64, error) {
+	return d.readFrom(r)
+}
+
+// Consume writes buffers received from ch into the digest in the order
+// they are received, until ch is closed. Nil and empty buffers are
+// ignored. If Write fails, Consume keeps draining ch without writing, so
+// that senders are not blocked, and returns the error after ch is closed.
+func (d *Digest) Consume(ch <-chan []byte) error {
+	var err error
+	for b := range ch {
+		if err == nil {
+			_, err = d.Write(b)
+		}
+	}
+	return err
+}
+
+// AbsorbBuffered writes the next n bytes from br into the digest, reading
+// them directly from the buffer of br. If fewer than n bytes are available,
+// it returns io.ErrUnexpectedEOF.
+func (d *Digest) AbsorbBuffered(br *bufio.Reader, n int) error {
+	for n > 0 {
+		b, err := br.Peek(min(n, br.Size()))
+		d.Write(b)
+		br.Discard(len(b))
+		n -= len(b)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes the contents of the named file into d.
+func (d *Digest) writeFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = d.readFrom(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// SumFile256 returns the BLAKE-256 checksum of the named file.
+func SumFile256(path string) (Hash256, error) {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	if err := d.writeFile(path); err != nil {
+		return Hash256{}, err
+	}
+	return d.checkSum(), nil
+}
+
+// SumFiles256 returns the BLAKE-256 checksum of the concatenated contents
+// of the named files.
+func SumFiles256(paths []string) (Hash256, error) {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	for _, path := range paths {
+		if err := d.writeFile(path); err != nil {
+			return Hash256{}, err
+		}
+	}
+	return d.checkSum(), nil
+}
+
+// SumFile224 returns the BLAKE-224 checksum of the named file.
+func SumFile224(path string) (sum224 Hash224, err error) {
+	var d Digest
+	d.hashSize = 224
+	d.Reset()
+	if err = d.writeFile(path); err != nil {
+		return
+	}
+	sum := d.checkSum()
+	copy(sum224[:], sum[:Size224])
+	return
+}
@@ -0,0 +1,107 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "hash"
+
+// CanContinue reports whether more data can be written after Sum.
+// It always returns true: Sum doesn't change the state of the digest, and
+// writing after Sum continues hashing the same message. Use NewStrict to
+// get a hash which disallows this.
+func (d *Digest) CanContinue() bool { return true }
+
+// strictDigest is a digest which panics on Write after Sum.
+type strictDigest struct {
+	h      Digest
+	summed bool
+}
+
+func (d *strictDigest) Reset() {
+	d.h.Reset()
+	d.summed = false
+}
+
+func (d *strictDigest) Size() int { return d.h.Size() }
+
+func (d *strictDigest) BlockSize() int { return BlockSize }
+
+func (d *strictDigest) Write(p []byte) (int, error) {
+	if d.summed {
+		panic("blake256: Write after Sum")
+	}
+	return d.h.Write(p)
+}
+
+func (d *strictDigest) Sum(in []byte) []byte {
+	d.summed = true
+	return d.h.Sum(in)
+}
+
+// NewStrict returns a new hash.Hash computing the BLAKE-256 checksum,
+// which panics if Write is called after Sum without Reset. It helps to
+// catch code that mistakenly continues hashing after finalization.
+func NewStrict() hash.Hash {
+	d := new(strictDigest)
+	d.h.hashSize = 256
+	d.Reset()
+	return d
+}
+
+// saltStrictDigest is a salted digest whose Reset clears the salt.
+type saltStrictDigest struct {
+	h      Digest
+	salted bool
+}
+
+func (d *saltStrictDigest) Reset() {
+	d.h.s = [4]uint32{}
+	d.h.Reset()
+	d.salted = false
+}
+
+// SetSalt sets salt to the given 16-byte slice. It must be called after
+// Reset before the digest is used again.
+func (d *saltStrictDigest) SetSalt(salt []byte) error {
+	if err := d.h.SetSalt(salt); err != nil {
+		return err
+	}
+	d.salted = true
+	return nil
+}
+
+func (d *saltStrictDigest) Size() int { return d.h.Size() }
+
+func (d *saltStrictDigest) BlockSize() int { return BlockSize }
+
+func (d *saltStrictDigest) Write(p []byte) (int, error) {
+	if !d.salted {
+		panic("blake256: salt is not set after Reset")
+	}
+	return d.h.Write(p)
+}
+
+func (d *saltStrictDigest) Sum(in []byte) []byte {
+	if !d.salted {
+		panic("blake256: salt is not set after Reset")
+	}
+	return d.h.Sum(in)
+}
+
+// NewSaltStrict is like NewSalt, but Reset of the returned hash also
+// clears the salt, and Write and Sum panic until a new salt is set with
+// its SetSalt([]byte) error method. This prevents a salt from silently
+// carrying over to an unrelated message, which happens with the standard
+// Reset that leaves salt intact.
+func NewSaltStrict(salt []byte) hash.Hash {
+	d := new(saltStrictDigest)
+	d.h.hashSize = 256
+	d.h.Reset()
+	d.h.setSalt(salt)
+	d.salted = true
+	return d
+}
@@ -0,0 +1,78 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import "sort"
+
+// StructHasher writes typed values into the embedded digest using a stable
+// canonical encoding:
+//
+//   - AddUint32 and AddUint64 write 4 and 8 big-endian bytes;
+//   - AddString and AddBytes write the length as 8 big-endian bytes
+//     followed by the contents.
+//
+// Strings and byte slices with the same contents are encoded identically.
+// AddUint32 and AddUint64 are the same as WriteUint32 and WriteUint64 of
+// the embedded Digest.
+type StructHasher struct {
+	*Digest
+}
+
+// NewStructHasher returns a new StructHasher computing BLAKE-256.
+func NewStructHasher() StructHasher {
+	return StructHasher{NewDigest()}
+}
+
+// AddUint32 writes v as 4 big-endian bytes.
+func (s StructHasher) AddUint32(v uint32) { s.WriteUint32(v) }
+
+// AddUint64 writes v as 8 big-endian bytes.
+func (s StructHasher) AddUint64(v uint64) { s.WriteUint64(v) }
+
+// AddString writes the length of v and its contents.
+func (s StructHasher) AddString(v string) {
+	s.AddUint64(uint64(len(v)))
+	s.Write([]byte(v))
+}
+
+// AddBytes writes the length of v and its contents.
+func (s StructHasher) AddBytes(v []byte) {
+	s.AddUint64(uint64(len(v)))
+	s.Write(v)
+}
+
+// SumMap256 returns the BLAKE-256 checksum of m in a canonical encoding
+// that doesn't depend on map iteration order: for each key in ascending
+// byte order, the key and then its value are written as by AddString and
+// AddBytes, that is, each as its length in 8 big-endian bytes followed by
+// its contents.
+func SumMap256(m map[string][]byte) Hash256 {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s := NewStructHasher()
+	for _, k := range keys {
+		s.AddString(k)
+		s.AddBytes(m[k])
+	}
+	return s.checkSum()
+}
+
+// ChunkFingerprint returns the BLAKE-256 checksum of index as 4 big-endian
+// bytes followed by data, so that identical data at different positions
+// has different fingerprints.
+func ChunkFingerprint(index uint32, data []byte) Hash256 {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	d.WriteUint32(index)
+	d.Write(data)
+	return d.checkSum()
+}
@@ -0,0 +1,176 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+)
+
+// Sum256x8 returns the BLAKE-256 checksums of eight inputs. If all inputs
+// have the same length and the CPU supports AVX-512, they are hashed in
+// parallel using SIMD instructions; otherwise they are hashed one by one.
+// Each result is equal to Sum256 of the corresponding input.
+//
+// There are no four- or two-lane implementations, so without AVX-512 the
+// inputs are always hashed one by one.
+func Sum256x8(in [8][]byte) (out [8]Hash256) {
+	if sameLength(&in) {
+		return (*x8impl.Load()).sum(&in)
+	}
+	return sum256x8Generic(&in)
+}
+
+type x8Implementation struct {
+	name string
+	sum  func(in *[8][]byte) [8]Hash256
+}
+
+// x8Implementations lists implementations of Sum256x8 for inputs of the
+// same length supported by the CPU.
+var x8Implementations = func() []*x8Implementation {
+	impls := []*x8Implementation{{"generic", sum256x8Generic}}
+	if hasAVX512 {
+		impls = append(impls, &x8Implementation{"avx512", sum256x8})
+	}
+	return impls
+}()
+
+// x8impl is the implementation used by Sum256x8.
+var x8impl atomic.Pointer[x8Implementation]
+
+func init() {
+	x8impl.Store(x8Implementations[len(x8Implementations)-1])
+}
+
+// CalibrateBest measures the speed of each implementation of Sum256x8
+// supported by the CPU and selects the fastest one for subsequent calls,
+// instead of relying on CPU features alone. It is not called by default;
+// it takes a few milliseconds, so call it once at startup if needed.
+// Results don't depend on the selected implementation.
+func CalibrateBest() {
+	var in [8][]byte
+	buf := make([]byte, 8*4096)
+	for i := range in {
+		in[i] = buf[i*4096 : (i+1)*4096]
+	}
+	best := x8Implementations[0]
+	var bestTime time.Duration
+	for _, impl := range x8Implementations {
+		impl.sum(&in) // warm up
+		// Take the fastest of several runs to reduce noise.
+		var elapsed time.Duration
+		for i := 0; i < 5; i++ {
+			start := time.Now()
+			impl.sum(&in)
+			if d := time.Since(start); i == 0 || d < elapsed {
+				elapsed = d
+			}
+		}
+		if bestTime == 0 || elapsed < bestTime {
+			best, bestTime = impl, elapsed
+		}
+	}
+	x8impl.Store(best)
+}
+
+func sum256x8Generic(in *[8][]byte) (out [8]Hash256) {
+	for i := range in {
+		out[i] = Sum256(in[i])
+	}
+	return
+}
+
+func sameLength(in *[8][]byte) bool {
+	for i := range in {
+		if len(in[i]) != len(in[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// cstx8 holds constants broadcast to eight lanes.
+var cstx8 = func() (c [16][8]uint32) {
+	for i, v := range [16]uint32{
+		cst0, cst1, cst2, cst3, cst4, cst5, cst6, cst7,
+		cst8, cst9, cst10, cst11, cst12, cst13, cst14, cst15,
+	} {
+		for j := range c[i] {
+			c[i][j] = v
+		}
+	}
+	return
+}()
+
+// compress8 compresses one block of each of eight inputs with
+// the given counter.
+func compress8(h *[8][8]uint32, m *[16][8]uint32, t uint64, nullt bool) {
+	var v [8][8]uint32
+	for j := 0; j < 8; j++ {
+		v[0][j], v[1][j], v[2][j], v[3][j] = cst0, cst1, cst2, cst3
+		v[4][j], v[5][j], v[6][j], v[7][j] = cst4, cst5, cst6, cst7
+		if !nullt {
+			v[4][j] ^= uint32(t)
+			v[5][j] ^= uint32(t)
+			v[6][j] ^= uint32(t >> 32)
+			v[7][j] ^= uint32(t >> 32)
+		}
+	}
+	block8AVX512(h, &v, m, &cstx8)
+}
+
+// sum256x8 hashes eight inputs of the same length in parallel.
+func sum256x8(in *[8][]byte) (out [8]Hash256) {
+	var h [8][8]uint32
+	for i := range h {
+		for j := range h[i] {
+			h[i][j] = iv256[i]
+		}
+	}
+	var m [16][8]uint32
+	n := len(in[0]) &^ (BlockSize - 1)
+	var t uint64
+	for off := 0; off < n; off += BlockSize {
+		for j := range in {
+			p := in[j][off : off+BlockSize]
+			for i := range m {
+				m[i][j] = binary.BigEndian.Uint32(p[4*i:])
+			}
+		}
+		t += 512
+		compress8(&h, &m, t, false)
+	}
+
+	// Final blocks have the same layout for all inputs.
+	var pad [8][2 * BlockSize]byte
+	var np int
+	var l uint64
+	for j := range in {
+		d := Digest{hashSize: 256, t: t}
+		d.nx = copy(d.x[:], in[j][n:])
+		np, l = d.padding(&pad[j], 0x01)
+	}
+	for off := 0; off < np; off += BlockSize {
+		for j := range pad {
+			for i := range m {
+				m[i][j] = binary.BigEndian.Uint32(pad[j][off+4*i:])
+			}
+		}
+		// The counter of a block that contains no message bits is zero.
+		compress8(&h, &m, l, off > 0 || len(in[0]) == n)
+	}
+
+	for j := range out {
+		for i := range h {
+			binary.BigEndian.PutUint32(out[j][4*i:], h[i][j])
+		}
+	}
+	return
+}
@@ -0,0 +1,16 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+//go:build !amd64 || purego
+
+package blake256
+
+const hasAVX512 = false
+
+func block8AVX512(h *[8][8]uint32, v *[8][8]uint32, m *[16][8]uint32, cst *[16][8]uint32) {
+	panic("blake256: AVX-512 is not supported")
+}
@@ -0,0 +1,65 @@
+#!/usr/bin/env python3
+# Reference implementation used to generate ref_vectors.txt and the
+# non-byte-aligned entries of ref_kat_256.txt. Run it to self-check.
+# Straightforward BLAKE-256/224 reference following the specification
+# (bit-oriented padding, salt support), written for this package's tests.
+# The self-check uses the zero-message vectors from the BLAKE submission
+# and the widely published checksums of "" and the "quick brown fox"
+# sentence, which don't come from this package.
+MASK=0xffffffff
+C=[0x243F6A88,0x85A308D3,0x13198A2E,0x03707344,0xA4093822,0x299F31D0,0x082EFA98,0xEC4E6C89,
+   0x452821E6,0x38D01377,0xBE5466CF,0x34E90C6C,0xC0AC29B7,0xC97C50DD,0x3F84D5B5,0xB5470917]
+SIGMA=[[0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15],[14,10,4,8,9,15,13,6,1,12,0,2,11,7,5,3],
+[11,8,12,0,5,2,15,13,10,14,3,6,7,1,9,4],[7,9,3,1,13,12,11,14,2,6,5,10,4,0,15,8],
+[9,0,5,7,2,4,10,15,14,1,11,12,6,8,3,13],[2,12,6,10,0,11,8,3,4,13,7,5,15,14,1,9],
+[12,5,1,15,14,13,4,10,0,7,6,3,9,2,8,11],[13,11,7,14,12,1,3,9,5,0,15,4,8,6,2,10],
+[6,15,14,9,11,3,0,8,12,2,13,7,1,4,10,5],[10,2,8,4,7,6,1,5,15,11,9,14,3,12,13,0]]
+IV256=[0x6A09E667,0xBB67AE85,0x3C6EF372,0xA54FF53A,0x510E527F,0x9B05688C,0x1F83D9AB,0x5BE0CD19]
+IV224=[0xC1059ED8,0x367CD507,0x3070DD17,0xF70E5939,0xFFC00B31,0x68581511,0x64F98FA7,0xBEFA4FA4]
+def rotr(x,n): return ((x>>n)|(x<<(32-n)))&MASK
+def G(v,m,r,a,b,c,d,i):
+    s=SIGMA[r%10]
+    v[a]=(v[a]+v[b]+(m[s[2*i]]^C[s[2*i+1]]))&MASK; v[d]=rotr(v[d]^v[a],16)
+    v[c]=(v[c]+v[d])&MASK; v[b]=rotr(v[b]^v[c],12)
+    v[a]=(v[a]+v[b]+(m[s[2*i+1]]^C[s[2*i]]))&MASK; v[d]=rotr(v[d]^v[a],8)
+    v[c]=(v[c]+v[d])&MASK; v[b]=rotr(v[b]^v[c],7)
+def compress(h,blk,s,t):
+    m=[int.from_bytes(blk[4*i:4*i+4],'big') for i in range(16)]
+    t0,t1=t&MASK,(t>>32)&MASK
+    v=h[:]+[s[0]^C[0],s[1]^C[1],s[2]^C[2],s[3]^C[3],t0^C[4],t0^C[5],t1^C[6],t1^C[7]]
+    for r in range(14):
+        G(v,m,r,0,4,8,12,0);G(v,m,r,1,5,9,13,1);G(v,m,r,2,6,10,14,2);G(v,m,r,3,7,11,15,3)
+        G(v,m,r,0,5,10,15,4);G(v,m,r,1,6,11,12,5);G(v,m,r,2,7,8,13,6);G(v,m,r,3,4,9,14,7)
+    return [h[i]^s[i%4]^v[i]^v[i+8] for i in range(8)]
+def blake(msg,nbits=None,size=256,salt=b"\0"*16):
+    if nbits is None: nbits=len(msg)*8
+    bits=''.join(format(b,'08b') for b in msg)[:nbits]
+    L=nbits
+    bits+='1'
+    while len(bits)%512!=447: bits+='0'
+    bits+='1' if size==256 else '0'
+    bits+=format(L,'064b')
+    data=int(bits,2).to_bytes(len(bits)//8,'big')
+    s=[int.from_bytes(salt[4*i:4*i+4],'big') for i in range(4)]
+    h=(IV256 if size==256 else IV224)[:]
+    nblocks=len(data)//64
+    for i in range(nblocks):
+        # counter: number of message bits up to and including this block;
+        # zero if the block contains no message bits.
+        start=i*512
+        if start>=L: t=0
+        else: t=min(L,start+512)
+        h=compress(h,data[64*i:64*i+64],s,t)
+    out=b''.join(x.to_bytes(4,'big') for x in h)
+    return out[:size//8]
+if __name__=='__main__':
+    assert blake(b"").hex()=="716f6e863f744b9ac22c97ec7b76ea5f5908bc5b2f67c61510bfc4751384ea7a"
+    assert blake(b"\0"*72).hex()=="d419bad32d504fb7d44d460c42c5593fe544fa4c135dec31e21bd9abdcc22d41"
+    assert blake(b"\0"*72,size=224).hex()=="f5aa00dd1cb847e3140372af7b5c46b4888d82c8c0a917913cfb5d04"
+    assert blake(b"",size=224).hex()=="7dc5313b1c04512a174bd6503b89607aecbee0903d40a8a569c94eed"
+    fox=b"The quick brown fox jumps over the lazy dog"
+    assert blake(fox).hex()=="7576698ee9cad30173080678e5965916adbb11cb5245d386bf1ffda1cb26c9d7"
+    assert blake(fox,size=224).hex()=="c8e92d7088ef87c1530aee2ad44dc720cc10589cc2ec58f95a15e51b"
+    assert blake(b"Lorem ipsum dolor sit amet, consectetur adipiscing elit. Donec a diam lectus. Sed sit amet ipsum mauris. Maecenas congu").hex()=="af95fffc7768821b1e08866a2f9f66916762bfc9d71c4acb5fd515f31fd6785a"
+    assert blake(b"It's so salty out there!",salt=b"SALTsaltSaltSALT").hex()=="88cc11889bbbee42095337fe2153c591971f94fbf8fe540d3c7e9f1700ab2d0c"
+    print("ok")
@@ -0,0 +1,164 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// maxValueDepth limits nesting of values hashed by SumValue256,
+// which also stops it on cyclic values.
+const maxValueDepth = 1000
+
+// SumValue256 returns the BLAKE-256 checksum of a canonical encoding of v.
+// Values of the same type that are deeply equal, as reported by
+// reflect.DeepEqual, have the same checksum, except for floating-point
+// numbers, which are compared by their bits. Values of different types may
+// have the same encoding; types and field names are not encoded.
+//
+// The encoding is built from v as follows:
+//
+//   - booleans are 1 byte, 0 or 1;
+//   - signed integers are converted to int64, unsigned ones to uint64, and
+//     floating-point numbers to float64 bits, and written as 8 big-endian
+//     bytes; complex numbers are written as their real and imaginary parts;
+//   - strings are written as their length in 8 big-endian bytes followed
+//     by their contents, the same way as by StructHasher;
+//   - slices and arrays are written as the number of elements in 8
+//     big-endian bytes followed by the elements; elements of byte slices
+//     and arrays are written as single bytes, so []byte is encoded as
+//     a string with the same contents;
+//   - structs are written as all their fields, exported or not, in
+//     declaration order;
+//   - maps are written as the number of entries in 8 big-endian bytes
+//     followed by the keys and values, ordered by the encoding of keys;
+//   - pointers and interfaces are written as 0 if nil, otherwise as 1
+//     followed by the value they point to or contain. Pointer addresses
+//     don't affect the result.
+//
+// SumValue256 returns an error if v contains functions, channels or unsafe
+// pointers, or if it is nested too deeply, which includes cyclic values.
+func SumValue256(v any) (Hash256, error) {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	if err := writeValue(&d, reflect.ValueOf(&v).Elem(), 0); err != nil {
+		return Hash256{}, err
+	}
+	return d.checkSum(), nil
+}
+
+// writeValue writes the encoding of v described in SumValue256 to w.
+func writeValue(w io.Writer, v reflect.Value, depth int) error {
+	if depth > maxValueDepth {
+		return errors.New("blake256: value nested too deeply or cyclic")
+	}
+	depth++
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			w.Write([]byte{1})
+		} else {
+			w.Write([]byte{0})
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(w, uint64(v.Int()))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(w, v.Uint())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		writeUint64(w, math.Float64bits(v.Float()))
+		return nil
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		writeUint64(w, math.Float64bits(real(c)))
+		writeUint64(w, math.Float64bits(imag(c)))
+		return nil
+	case reflect.String:
+		writeUint64(w, uint64(v.Len()))
+		io.WriteString(w, v.String())
+		return nil
+	case reflect.Slice, reflect.Array:
+		writeUint64(w, uint64(v.Len()))
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Slice {
+				w.Write(v.Bytes())
+				return nil
+			}
+			b := make([]byte, v.Len())
+			for i := range b {
+				b[i] = byte(v.Index(i).Uint())
+			}
+			w.Write(b)
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := writeValue(w, v.Index(i), depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := writeValue(w, v.Field(i), depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		// Entries are ordered by the encoding of keys, so only the keys
+		// are encoded in advance; values are written directly.
+		type entry struct {
+			k []byte
+			v reflect.Value
+		}
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var k bytes.Buffer
+			if err := writeValue(&k, iter.Key(), depth); err != nil {
+				return err
+			}
+			entries = append(entries, entry{k.Bytes(), iter.Value()})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].k, entries[j].k) < 0
+		})
+		writeUint64(w, uint64(len(entries)))
+		for _, e := range entries {
+			w.Write(e.k)
+			if err := writeValue(w, e.v, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			w.Write([]byte{0})
+			return nil
+		}
+		w.Write([]byte{1})
+		return writeValue(w, v.Elem(), depth)
+	}
+	return fmt.Errorf("blake256: cannot hash value of type %s", v.Type())
+}
+
+func writeUint64(w io.Writer, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	w.Write(b[:])
+}
@@ -0,0 +1,59 @@
+// Written in 2011-2012 by Dmitry Chestnykh.
+//
+// To the extent possible under law, the author have dedicated all copyright
+// and related and neighboring rights to this software to the public domain
+// worldwide. This software is distributed without any warranty.
+// http://creativecommons.org/publicdomain/zero/1.0/
+
+package blake256
+
+// Windowed keeps a sliding window of the last bytes of a stream and computes
+// BLAKE-256 checksum of the window contents. BLAKE-256 is not a rolling
+// hash, so each checksum is computed from scratch in O(window size) time.
+type Windowed struct {
+	buf  []byte // ring buffer
+	pos  int    // position of the next byte in buf
+	full bool   // whether buf has been filled
+}
+
+// NewWindowed returns a new Windowed with the given window size in bytes.
+// It panics if windowSize is not positive.
+func NewWindowed(windowSize int) *Windowed {
+	if windowSize <= 0 {
+		panic("blake256: invalid window size")
+	}
+	return &Windowed{buf: make([]byte, windowSize)}
+}
+
+// Advance adds b to the window, dropping the oldest byte if the window
+// is full.
+func (w *Windowed) Advance(b byte) {
+	w.buf[w.pos] = b
+	w.pos++
+	if w.pos == len(w.buf) {
+		w.pos = 0
+		w.full = true
+	}
+}
+
+// Window appends the current window contents, oldest first, to in and
+// returns the resulting slice.
+func (w *Windowed) Window(in []byte) []byte {
+	if w.full {
+		in = append(in, w.buf[w.pos:]...)
+	}
+	return append(in, w.buf[:w.pos]...)
+}
+
+// CurrentHash returns the BLAKE-256 checksum of the current window contents.
+// Before the window is filled, it covers all bytes added so far.
+func (w *Windowed) CurrentHash() Hash256 {
+	var d Digest
+	d.hashSize = 256
+	d.Reset()
+	if w.full {
+		d.Write(w.buf[w.pos:])
+	}
+	d.Write(w.buf[:w.pos])
+	return d.checkSum()
+}